@@ -0,0 +1,106 @@
+package golog
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+/* A failed flush drops the buffered data, bufio would otherwise refuse all further writes. */
+func (fl *FileLog) flush() (err error) {
+	if fl.buf == nil || fl.buf.Buffered() == 0 {
+		return nil
+	}
+	if err = fl.buf.Flush(); err != nil {
+		fl.buf.Reset(fl.writer)
+	}
+	return
+}
+
+/* Must be called with lock held. */
+func (l *Logger) flush() {
+	if err := l.out.flush(); err != nil && l.onError != nil {
+		l.onError(err)
+	}
+	for _, o := range l.outputs {
+		if err := o.flush(); err != nil && l.onError != nil {
+			l.onError(err)
+		}
+	}
+	for _, dests := range l.byLevel {
+		for _, o := range dests {
+			if err := o.flush(); err != nil && l.onError != nil {
+				l.onError(err)
+			}
+		}
+	}
+	if l.console != nil {
+		if err := l.console.flush(); err != nil && l.onError != nil {
+			l.onError(err)
+		}
+	}
+}
+
+/* Flushes, then syncs the writer. Files other than regular ones, like terminals and pipes, are not synced. */
+func (fl *FileLog) sync() error {
+	if err := fl.flush(); err != nil {
+		return err
+	}
+	s, ok := fl.writer.(syncer)
+	if !ok {
+		return nil
+	}
+	if f, ok := fl.writer.(*os.File); ok {
+		if st, err := f.Stat(); err != nil || !st.Mode().IsRegular() {
+			return nil
+		}
+	}
+	return s.Sync()
+}
+
+/*
+ * Writes everything queued so far and syncs the outputs to stable storage.
+ * Returns the first error, all outputs are synced regardless.
+ */
+func (l *Logger) Sync() (err error) {
+	l.Flush()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	err = l.out.sync()
+	for _, o := range l.outputs {
+		if e := o.sync(); err == nil {
+			err = e
+		}
+	}
+	for _, dests := range l.byLevel {
+		for _, o := range dests {
+			if e := o.sync(); err == nil {
+				err = e
+			}
+		}
+	}
+	return
+}
+
+func Sync() error {
+	return std.Sync()
+}
+
+func (l *Logger) flushOutputs() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.flush()
+}
+
+/*
+ * Output is buffered and by default flushed whenever the queue runs empty.
+ * With a positive interval it is flushed at most that long after a write
+ * instead, trading latency for fewer syscalls under sustained load.
+ */
+func (l *Logger) SetFlushInterval(d time.Duration) {
+	atomic.StoreInt64(&l.flushEvery, int64(d))
+}
+
+func (l *Logger) loadFlushInterval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&l.flushEvery))
+}
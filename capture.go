@@ -0,0 +1,45 @@
+package golog
+
+import "io"
+
+type captureHandler struct {
+	msgs []Message
+}
+
+func (c *captureHandler) Handle(msg Message) error {
+	c.msgs = append(c.msgs, msg)
+	return nil
+}
+
+/*
+ * Runs fn with every output of the logger, the console mirror and the ring
+ * buffer included, replaced by an in-memory capture, and returns the
+ * messages written meanwhile. The previous outputs are
+ * restored afterwards, even if fn panics. Meant for tests: messages from
+ * other goroutines logging at the same time are captured as well.
+ */
+func (l *Logger) CaptureOutput(fn func()) (msgs []Message) {
+	l.Flush()
+	capture := &captureHandler{}
+	l.lock.Lock()
+	out, outputs, byLevel, handlers := l.out, l.outputs, l.byLevel, l.handlers
+	console, ring := l.console, l.ring
+	l.out = NewWriter(io.Discard)
+	l.outputs, l.byLevel, l.handlers = nil, nil, []Handler{capture}
+	l.console, l.ring = nil, nil
+	l.lock.Unlock()
+	defer func() {
+		l.Flush()
+		l.lock.Lock()
+		l.out, l.outputs, l.byLevel, l.handlers = out, outputs, byLevel, handlers
+		l.console, l.ring = console, ring
+		l.lock.Unlock()
+		msgs = capture.msgs
+	}()
+	fn()
+	return
+}
+
+func CaptureOutput(fn func()) []Message {
+	return std.CaptureOutput(fn)
+}
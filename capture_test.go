@@ -0,0 +1,27 @@
+package golog
+
+import (
+	"testing"
+)
+
+func TestCaptureOutput(t *testing.T) {
+	l, buf := newPlainLogger(t)
+	console := new(syncBuffer)
+	l.SetConsoleLevel(WARN)
+	l.lock.Lock()
+	l.console = NewWriter(console)
+	l.lock.Unlock()
+	l.EnableRingBuffer(4)
+	msgs := l.CaptureOutput(func() {
+		l.Warn("captured")
+	})
+	if len(msgs) != 1 || msgs[0].Text() != "captured" {
+		t.Fatalf("captured %v, want the warning", msgs)
+	}
+	l.Warn("after")
+	checkLines(t, l, buf, "[ WARN] after")
+	checkLines(t, l, console, "[ WARN] after")
+	if got := l.RingBufferContents(); len(got) != 1 || got[0] != "[ WARN] after" {
+		t.Errorf("ring buffer holds %q, want only the line after the capture", got)
+	}
+}
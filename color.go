@@ -0,0 +1,59 @@
+package golog
+
+import (
+	"os"
+)
+
+type colorMode int
+
+const (
+	colorAuto colorMode = iota
+	colorOn
+	colorOff
+)
+
+var level_color = [...]string{
+	"\x1b[34m",   // TRACE
+	"\x1b[36m",   // DEBUG
+	"\x1b[32m",   // INFO
+	"\x1b[33m",   // WARN
+	"\x1b[31m",   // ERROR
+	"\x1b[1;31m", // FATAL
+}
+
+const colorReset = "\x1b[0m"
+
+/* See https://no-color.org, only consulted while the color mode is automatic. */
+var no_color = os.Getenv("NO_COLOR") != ""
+
+func colorize(level Level, s string) string {
+	if level < 0 || int(level) >= len(level_color) {
+		return s
+	}
+	return level_color[level] + s + colorReset
+}
+
+/*
+ * By default only outputs attached to a terminal are colored, and none if
+ * NO_COLOR is set. SetColor overrides both.
+ */
+func (l *Logger) SetColor(color bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if color {
+		l.color = colorOn
+	} else {
+		l.color = colorOff
+	}
+}
+
+/* Must be called with lock held. */
+func (l *Logger) useColor(o *FileLog) bool {
+	switch l.color {
+	case colorOn:
+		return true
+	case colorOff:
+		return false
+	}
+	return o.tty && !no_color
+}
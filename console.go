@@ -0,0 +1,27 @@
+package golog
+
+import (
+	"os"
+)
+
+/*
+ * Messages at or above level are also written to stderr, unless they already
+ * went there through another output. INVALID disables the mirror.
+ */
+func (l *Logger) SetConsoleLevel(level Level) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.consoleLevel = level
+	if level == INVALID {
+		if l.console != nil {
+			l.console.flush()
+		}
+		l.console = nil
+	} else if l.console == nil {
+		l.console = l.levelOutput(os.Stderr)
+	}
+}
+
+func SetConsoleLevel(level Level) {
+	std.SetConsoleLevel(level)
+}
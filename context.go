@@ -0,0 +1,130 @@
+package golog
+
+import (
+	"context"
+)
+
+type contextKey struct {
+	name string
+	key  interface{}
+}
+
+/* Values stored in a context under key are logged as field name by the *Ctx functions. */
+func (l *Logger) RegisterContextKey(name string, key interface{}) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.ctxKeys = append(l.ctxKeys, contextKey{name: name, key: key})
+}
+
+func RegisterContextKey(name string, key interface{}) {
+	std.RegisterContextKey(name, key)
+}
+
+func (l *Logger) contextEntry(ctx context.Context) *Entry {
+	l.lock.Lock()
+	keys := l.ctxKeys
+	l.lock.Unlock()
+	e := &Entry{logger: l}
+	for _, k := range keys {
+		if v := ctx.Value(k.key); v != nil {
+			e.fields = append(e.fields, Field{Key: k.name, Value: v})
+		}
+	}
+	return e
+}
+
+func (l *Logger) FatalCtx(ctx context.Context, msg string) {
+	l.log(FATAL, 0, msg, l.contextEntry(ctx))
+}
+
+func (l *Logger) FatalfCtx(ctx context.Context, format string, a ...interface{}) {
+	l.logf(FATAL, 0, format, a, l.contextEntry(ctx))
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, msg string) {
+	l.log(ERROR, 0, msg, l.contextEntry(ctx))
+}
+
+func (l *Logger) ErrorfCtx(ctx context.Context, format string, a ...interface{}) {
+	l.logf(ERROR, 0, format, a, l.contextEntry(ctx))
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, msg string) {
+	l.log(WARN, 0, msg, l.contextEntry(ctx))
+}
+
+func (l *Logger) WarnfCtx(ctx context.Context, format string, a ...interface{}) {
+	l.logf(WARN, 0, format, a, l.contextEntry(ctx))
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, msg string) {
+	l.log(INFO, 0, msg, l.contextEntry(ctx))
+}
+
+func (l *Logger) InfofCtx(ctx context.Context, format string, a ...interface{}) {
+	l.logf(INFO, 0, format, a, l.contextEntry(ctx))
+}
+
+func (l *Logger) DebugCtx(ctx context.Context, msg string) {
+	l.log(DEBUG, 0, msg, l.contextEntry(ctx))
+}
+
+func (l *Logger) DebugfCtx(ctx context.Context, format string, a ...interface{}) {
+	l.logf(DEBUG, 0, format, a, l.contextEntry(ctx))
+}
+
+func (l *Logger) TraceCtx(ctx context.Context, msg string) {
+	l.log(TRACE, 0, msg, l.contextEntry(ctx))
+}
+
+func (l *Logger) TracefCtx(ctx context.Context, format string, a ...interface{}) {
+	l.logf(TRACE, 0, format, a, l.contextEntry(ctx))
+}
+
+func FatalCtx(ctx context.Context, msg string) {
+	std.log(FATAL, 0, msg, std.contextEntry(ctx))
+}
+
+func FatalfCtx(ctx context.Context, format string, a ...interface{}) {
+	std.logf(FATAL, 0, format, a, std.contextEntry(ctx))
+}
+
+func ErrorCtx(ctx context.Context, msg string) {
+	std.log(ERROR, 0, msg, std.contextEntry(ctx))
+}
+
+func ErrorfCtx(ctx context.Context, format string, a ...interface{}) {
+	std.logf(ERROR, 0, format, a, std.contextEntry(ctx))
+}
+
+func WarnCtx(ctx context.Context, msg string) {
+	std.log(WARN, 0, msg, std.contextEntry(ctx))
+}
+
+func WarnfCtx(ctx context.Context, format string, a ...interface{}) {
+	std.logf(WARN, 0, format, a, std.contextEntry(ctx))
+}
+
+func InfoCtx(ctx context.Context, msg string) {
+	std.log(INFO, 0, msg, std.contextEntry(ctx))
+}
+
+func InfofCtx(ctx context.Context, format string, a ...interface{}) {
+	std.logf(INFO, 0, format, a, std.contextEntry(ctx))
+}
+
+func DebugCtx(ctx context.Context, msg string) {
+	std.log(DEBUG, 0, msg, std.contextEntry(ctx))
+}
+
+func DebugfCtx(ctx context.Context, format string, a ...interface{}) {
+	std.logf(DEBUG, 0, format, a, std.contextEntry(ctx))
+}
+
+func TraceCtx(ctx context.Context, msg string) {
+	std.log(TRACE, 0, msg, std.contextEntry(ctx))
+}
+
+func TracefCtx(ctx context.Context, format string, a ...interface{}) {
+	std.logf(TRACE, 0, format, a, std.contextEntry(ctx))
+}
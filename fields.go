@@ -0,0 +1,322 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+/* An Entry carries fields attached to every message logged through it. */
+type Entry struct {
+	logger *Logger
+	fields []Field
+	prefix string
+	name   string
+}
+
+func (l *Logger) WithFields(fields map[string]interface{}) *Entry {
+	return (&Entry{logger: l}).WithFields(fields)
+}
+
+/* Keys already present are overridden. Fields are rendered in key order. */
+func (e *Entry) WithFields(fields map[string]interface{}) *Entry {
+	list := make([]Field, 0, len(fields))
+	for k, v := range fields {
+		list = append(list, Field{Key: k, Value: v})
+	}
+	return e.with(list)
+}
+
+/* Like WithFields, for fields passed to a logging call. Fields with an empty key are skipped. */
+func (e *Entry) with(fields []Field) *Entry {
+	if len(fields) == 0 {
+		return e
+	}
+	merged := make(map[string]interface{}, len(e.fields)+len(fields))
+	for _, f := range e.fields {
+		merged[f.Key] = f.Value
+	}
+	for _, f := range fields {
+		if f.Key != "" {
+			merged[f.Key] = f.Value
+		}
+	}
+	n := &Entry{logger: e.logger, fields: make([]Field, 0, len(merged)), prefix: e.prefix, name: e.name}
+	for k, v := range merged {
+		n.fields = append(n.fields, Field{Key: k, Value: v})
+	}
+	sort.Slice(n.fields, func(i, j int) bool {
+		return n.fields[i].Key < n.fields[j].Key
+	})
+	return n
+}
+
+/*
+ * Fields added to every message, before its own. Fields of the message
+ * override global ones with the same key. nil removes them.
+ */
+func (l *Logger) SetGlobalFields(fields map[string]interface{}) {
+	global := (&Entry{}).WithFields(fields).fields
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.globalFields = snapshot(global)
+}
+
+func SetGlobalFields(fields map[string]interface{}) {
+	std.SetGlobalFields(fields)
+}
+
+/* Must be called with lock held. */
+func (l *Logger) addGlobalFields(fields []Field) []Field {
+	if len(l.globalFields) == 0 {
+		return fields
+	}
+	merged := make([]Field, 0, len(l.globalFields)+len(fields))
+global:
+	for _, g := range l.globalFields {
+		for _, f := range fields {
+			if f.Key == g.Key {
+				continue global
+			}
+		}
+		merged = append(merged, g)
+	}
+	return append(merged, fields...)
+}
+
+func (l *Logger) with(fields []Field) *Entry {
+	if len(fields) == 0 {
+		return nil
+	}
+	return (&Entry{logger: l}).with(fields)
+}
+
+func Int(key string, v int) Field {
+	return Field{Key: key, Value: v}
+}
+
+func Str(key, v string) Field {
+	return Field{Key: key, Value: v}
+}
+
+func Dur(key string, d time.Duration) Field {
+	return Field{Key: key, Value: d}
+}
+
+/* The error field, like WithError. A nil error adds no field. */
+func Err(err error) Field {
+	if err == nil {
+		return Field{}
+	}
+	return Field{Key: "error", Value: err.Error()}
+}
+
+func immutable(v interface{}) bool {
+	switch v.(type) {
+	case nil, bool, string, int, int8, int16, int32, int64,
+		uint, uint8, uint16, uint32, uint64, float32, float64, time.Duration:
+		return true
+	}
+	return false
+}
+
+func (l *Logger) WithPrefix(prefix string) *Entry {
+	return &Entry{logger: l, prefix: prefix}
+}
+
+/*
+ * The prefix follows the logger prefix, and nested entries add theirs after
+ * the outer ones: WithPrefix("[svc]").WithPrefix("[handler]") gives "[svc][handler]".
+ */
+func (e *Entry) WithPrefix(prefix string) *Entry {
+	return &Entry{logger: e.logger, fields: e.fields, prefix: e.prefix + prefix, name: e.name}
+}
+
+func (l *Logger) WithError(err error) *Entry {
+	return (&Entry{logger: l}).WithError(err)
+}
+
+/* Attaches err as the error field, a nil error adds nothing. */
+func (e *Entry) WithError(err error) *Entry {
+	if err == nil {
+		return e
+	}
+	return e.WithFields(map[string]interface{}{"error": err.Error()})
+}
+
+/* Values other than plain scalars are rendered now, so later mutation doesn't leak into the log. */
+func snapshot(fields []Field) []Field {
+	if len(fields) == 0 {
+		return nil
+	}
+	s := make([]Field, len(fields))
+	for i, f := range fields {
+		s[i].Key = f.Key
+		if immutable(f.Value) {
+			s[i].Value = f.Value
+		} else {
+			s[i].Value = fmt.Sprint(f.Value)
+		}
+	}
+	return s
+}
+
+func needsQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+/* Common types are rendered without going through fmt. */
+func textValue(v interface{}) string {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Duration:
+		return v.String()
+	default:
+		s = fmt.Sprint(v)
+	}
+	if needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+/* Keys cannot be quoted, so space, '=', '"' and unprintable runes become '_'. */
+func textKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	if !needsQuote(key) {
+		return key
+	}
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+/* Like writeJSONFields, keys colliding with one of reserved get a "fields." prefix. */
+func writeTextFields(buf *bytes.Buffer, fields []Field, reserved []string) {
+	for _, f := range fields {
+		key := textKey(f.Key)
+		for _, r := range reserved {
+			if strings.EqualFold(key, r) {
+				key = "fields." + key
+				break
+			}
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		buf.WriteString(textValue(f.Value))
+	}
+}
+
+var reserved_keys = []string{"level", "time", "file", "line", "func", "logger", "msg", "stack"}
+
+/* Keys colliding with one of reserved get a "fields." prefix. */
+func writeJSONFields(buf *bytes.Buffer, fields []Field, reserved []string) {
+	for _, f := range fields {
+		key := f.Key
+		for _, r := range reserved {
+			if strings.EqualFold(key, r) {
+				key = "fields." + key
+				break
+			}
+		}
+		buf.WriteByte(',')
+		jsonString(buf, key)
+		buf.WriteByte(':')
+		switch v := f.Value.(type) {
+		case string:
+			jsonString(buf, v)
+			continue
+		case int:
+			buf.WriteString(strconv.Itoa(v))
+			continue
+		case time.Duration:
+			buf.WriteString(strconv.FormatInt(int64(v), 10))
+			continue
+		}
+		if b, err := json.Marshal(f.Value); err == nil {
+			buf.Write(b)
+		} else {
+			jsonString(buf, fmt.Sprint(f.Value))
+		}
+	}
+}
+
+func (e *Entry) Fatal(msg string, fields ...Field) {
+	e.logger.log(FATAL, 0, msg, e.with(fields))
+}
+
+func (e *Entry) Fatalf(format string, a ...interface{}) {
+	e.logger.logf(FATAL, 0, format, a, e)
+}
+
+func (e *Entry) Error(msg string, fields ...Field) {
+	e.logger.log(ERROR, 0, msg, e.with(fields))
+}
+
+func (e *Entry) Errorf(format string, a ...interface{}) {
+	e.logger.logf(ERROR, 0, format, a, e)
+}
+
+func (e *Entry) Warn(msg string, fields ...Field) {
+	e.logger.log(WARN, 0, msg, e.with(fields))
+}
+
+func (e *Entry) Warnf(format string, a ...interface{}) {
+	e.logger.logf(WARN, 0, format, a, e)
+}
+
+func (e *Entry) Info(msg string, fields ...Field) {
+	e.logger.log(INFO, 0, msg, e.with(fields))
+}
+
+func (e *Entry) Infof(format string, a ...interface{}) {
+	e.logger.logf(INFO, 0, format, a, e)
+}
+
+func (e *Entry) Debug(msg string, fields ...Field) {
+	e.logger.log(DEBUG, 0, msg, e.with(fields))
+}
+
+func (e *Entry) Debugf(format string, a ...interface{}) {
+	e.logger.logf(DEBUG, 0, format, a, e)
+}
+
+func (e *Entry) Trace(msg string, fields ...Field) {
+	e.logger.log(TRACE, 0, msg, e.with(fields))
+}
+
+func (e *Entry) Tracef(format string, a ...interface{}) {
+	e.logger.logf(TRACE, 0, format, a, e)
+}
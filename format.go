@@ -0,0 +1,258 @@
+package golog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+/*
+ * Format is called by the daemon with the logger lock held, and must return a
+ * complete line. It must not call Logger methods taking the lock.
+ */
+type Formatter interface {
+	Format(l *Logger, msg *Message) []byte
+}
+
+var (
+	TEXT Formatter = textFormatter{}
+	JSON Formatter = jsonFormatter{}
+	/* JSON as understood by Google Cloud Logging when written to stdout or stderr. */
+	GCPJSON Formatter = gcpFormatter{}
+	LOGFMT  Formatter = logfmtFormatter{}
+)
+
+/*
+ * Messages written to w are rendered with f instead of the logger formatter,
+ * nil restores it. Applies to the output currently open for w, whether main,
+ * added or per level; a file replaced by Open starts with the logger formatter.
+ */
+func (l *Logger) SetOutputFormat(w io.Writer, f Formatter) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if o := l.findOutput(w); o != nil {
+		o.format = f
+	}
+}
+
+func SetOutputFormat(w io.Writer, f Formatter) {
+	std.SetOutputFormat(w, f)
+}
+
+/* The lines rendered for one message, formatted once per distinct formatter and coloring. */
+type lineCache struct {
+	msg   *Message
+	lines []renderedLine
+}
+
+type renderedLine struct {
+	format Formatter
+	color  bool
+	line   []byte
+}
+
+/* Formatters of uncomparable types are never considered equal, and simply format again. */
+func sameFormatter(a, b Formatter) bool {
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+/* Must be called with lock held. */
+func (c *lineCache) get(l *Logger, f Formatter, color bool) []byte {
+	for _, r := range c.lines {
+		if r.color == color && sameFormatter(r.format, f) {
+			return r.line
+		}
+	}
+	c.msg.color = color
+	line := f.Format(l, c.msg)
+	c.msg.color = false
+	c.lines = append(c.lines, renderedLine{f, color, line})
+	return line
+}
+
+/* Must be called with lock held. The line for o, in its formatter and coloring. */
+func (l *Logger) line(o *FileLog, c *lineCache) []byte {
+	f := o.format
+	if f == nil {
+		f = l.format
+	}
+	return c.get(l, f, l.useColor(o))
+}
+
+type textFormatter struct{}
+
+func (textFormatter) Format(l *Logger, msg *Message) []byte {
+	var buf bytes.Buffer
+	level := fmt.Sprintf("%*s", l.width(), l.levelName(msg.level))
+	if msg.color {
+		level = colorize(msg.level, level)
+	}
+	if l.timeFormat == "" {
+		fmt.Fprintf(&buf, "[%s]", level)
+	} else {
+		fmt.Fprintf(&buf, "[%s @ %s]", level, msg.time.Format(l.timeFormat))
+	}
+	prefix, name, message := l.prefix+msg.prefix, msg.name, msg.message
+	if l.escapeNL {
+		prefix = newline_escaper.Replace(prefix)
+		name = newline_escaper.Replace(name)
+		message = newline_escaper.Replace(message)
+	} else if l.indent != "" {
+		message = strings.Replace(message, "\n", "\n"+l.indent, -1)
+	}
+	if l.placement == PREFIX_BEFORE_CALLER {
+		buf.WriteString(prefix)
+		prefix = ""
+	}
+	if !msg.hasCaller() {
+		buf.WriteByte(' ')
+	} else if function := l.function(msg); function != "" {
+		fmt.Fprintf(&buf, "[%s:%d %s] ", l.file(msg), msg.caller.line, function)
+	} else {
+		fmt.Fprintf(&buf, "[%s:%d] ", l.file(msg), msg.caller.line)
+	}
+	if name != "" {
+		fmt.Fprintf(&buf, "[%s] ", name)
+	}
+	fmt.Fprintf(&buf, "%s%s", prefix, message)
+	writeTextFields(&buf, msg.fields, nil)
+	if msg.stack != "" && l.escapeNL {
+		buf.WriteByte(' ')
+		buf.WriteString(newline_escaper.Replace(strings.TrimSuffix(msg.stack, "\n")))
+	}
+	buf.WriteByte('\n')
+	if msg.stack != "" && !l.escapeNL {
+		buf.WriteString(msg.stack)
+	}
+	return buf.Bytes()
+}
+
+type jsonFormatter struct{}
+
+func jsonString(buf *bytes.Buffer, s string) {
+	b, _ := json.Marshal(s) // Marshaling a string never fails.
+	buf.Write(b)
+}
+
+func (jsonFormatter) Format(l *Logger, msg *Message) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`{"level":`)
+	jsonString(&buf, l.levelName(msg.level))
+	if l.timeFormat != "" {
+		buf.WriteString(`,"time":`)
+		jsonString(&buf, msg.time.Format(l.timeFormat))
+	}
+	if msg.hasCaller() {
+		buf.WriteString(`,"file":`)
+		jsonString(&buf, l.file(msg))
+		buf.WriteString(`,"line":`)
+		buf.WriteString(strconv.Itoa(msg.caller.line))
+		if function := l.function(msg); function != "" {
+			buf.WriteString(`,"func":`)
+			jsonString(&buf, function)
+		}
+	}
+	if msg.name != "" {
+		buf.WriteString(`,"logger":`)
+		jsonString(&buf, msg.name)
+	}
+	buf.WriteString(`,"msg":`)
+	jsonString(&buf, l.prefix+msg.prefix+msg.message)
+	writeJSONFields(&buf, msg.fields, reserved_keys)
+	if msg.stack != "" {
+		buf.WriteString(`,"stack":`)
+		jsonString(&buf, msg.stack)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+type gcpFormatter struct{}
+
+var gcp_severity = [...]string{"DEBUG", "DEBUG", "INFO", "WARNING", "ERROR", "CRITICAL"}
+
+var gcp_reserved_keys = []string{"severity", "message", "timestamp", "logger", "stack",
+	"logging.googleapis.com/sourceLocation"}
+
+/*
+ * Level names, time format and prefix placement don't apply, as Cloud
+ * Logging expects its own severities and an RFC 3339 timestamp.
+ */
+func (gcpFormatter) Format(l *Logger, msg *Message) []byte {
+	var buf bytes.Buffer
+	severity := "DEFAULT"
+	if msg.level >= 0 && int(msg.level) < len(gcp_severity) {
+		severity = gcp_severity[msg.level]
+	}
+	buf.WriteString(`{"severity":`)
+	jsonString(&buf, severity)
+	buf.WriteString(`,"timestamp":`)
+	jsonString(&buf, msg.time.Format(time.RFC3339Nano))
+	if msg.hasCaller() {
+		buf.WriteString(`,"logging.googleapis.com/sourceLocation":{"file":`)
+		jsonString(&buf, l.file(msg))
+		buf.WriteString(`,"line":`)
+		jsonString(&buf, strconv.Itoa(msg.caller.line))
+		if msg.caller.function != "" {
+			buf.WriteString(`,"function":`)
+			jsonString(&buf, msg.caller.function)
+		}
+		buf.WriteByte('}')
+	}
+	if msg.name != "" {
+		buf.WriteString(`,"logger":`)
+		jsonString(&buf, msg.name)
+	}
+	buf.WriteString(`,"message":`)
+	jsonString(&buf, l.prefix+msg.prefix+msg.message)
+	writeJSONFields(&buf, msg.fields, gcp_reserved_keys)
+	if msg.stack != "" {
+		buf.WriteString(`,"stack":`)
+		jsonString(&buf, msg.stack)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+type logfmtFormatter struct{}
+
+var logfmt_reserved_keys = []string{"ts", "level", "caller", "func", "logger", "msg", "stack"}
+
+/* Timestamps are RFC 3339 and levels lower case, as logfmt consumers expect. */
+func (logfmtFormatter) Format(l *Logger, msg *Message) []byte {
+	var buf bytes.Buffer
+	if l.timeFormat != "" {
+		buf.WriteString("ts=")
+		buf.WriteString(msg.time.Format(time.RFC3339Nano))
+		buf.WriteByte(' ')
+	}
+	buf.WriteString("level=")
+	buf.WriteString(textValue(strings.ToLower(l.levelName(msg.level))))
+	if msg.hasCaller() {
+		buf.WriteString(" caller=")
+		buf.WriteString(textValue(l.file(msg) + ":" + strconv.Itoa(msg.caller.line)))
+		if function := l.function(msg); function != "" {
+			buf.WriteString(" func=")
+			buf.WriteString(textValue(function))
+		}
+	}
+	if msg.name != "" {
+		buf.WriteString(" logger=")
+		buf.WriteString(textValue(msg.name))
+	}
+	buf.WriteString(" msg=")
+	buf.WriteString(textValue(l.prefix + msg.prefix + msg.message))
+	writeTextFields(&buf, msg.fields, logfmt_reserved_keys)
+	if msg.stack != "" {
+		buf.WriteString(" stack=")
+		buf.WriteString(textValue(msg.stack))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
@@ -0,0 +1,56 @@
+package golog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogfmtKeys(t *testing.T) {
+	l, buf := newPlainLogger(t)
+	l.SetFormat(LOGFMT)
+	l.Named("svc").Info("hello world", Dur("d", time.Second), Str("msg", "dup"), Str("Level", "x"), Str("ts", "y"))
+	l.Info("keys", Str("my key", "x"), Str("a=b\"c\n", "y"))
+	checkLines(t, l, buf,
+		`level=info logger=svc msg="hello world" fields.Level=x d=1s fields.msg=dup fields.ts=y`,
+		`level=info msg=keys a_b_c_=y my_key=x`)
+}
+
+func TestTextKeys(t *testing.T) {
+	l, buf := newPlainLogger(t)
+	l.Info("keys", Str("my key", "x"), Str("msg", "y"))
+	checkLines(t, l, buf, `[ INFO] keys msg=y my_key=x`)
+}
+
+func TestEscapeNewlines(t *testing.T) {
+	l, buf := newPlainLogger(t)
+	l.SetEscapeNewlines(true)
+	l.SetPrefix("p\n")
+	l.Named("a\nb").Info("one\ntwo\r", Str("k", "v\nw"))
+	l.ErrorErr(fmt.Errorf("outer\nline: %w", errors.New("inner")))
+	l.ErrorStack("stack")
+	l.Flush()
+	lines := buf.Lines()
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want one per message: %q", len(lines), lines)
+	}
+	if want := `[ INFO] [a\nb] p\none\ntwo\r k="v\nw"`; lines[0] != want {
+		t.Errorf("got %q, want %q", lines[0], want)
+	}
+	if want := `[ERROR] p\nouter\nline: inner caused by: inner`; lines[1] != want {
+		t.Errorf("got %q, want %q", lines[1], want)
+	}
+	if !strings.HasPrefix(lines[2], `[ERROR] p\nstack goroutine `) {
+		t.Errorf("got %q, want the stack on the message line", lines[2])
+	}
+
+	buf.Reset()
+	l.SetFormat(JSON)
+	l.Info("a\nb")
+	l.Flush()
+	if out := buf.String(); !strings.Contains(out, `"msg":"p\na\nb"`) {
+		t.Errorf("JSON output %q, want the message escaped once", out)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/jackyyf/golog
+
+go 1.24
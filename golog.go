@@ -1,19 +1,47 @@
 package golog
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type Level int
 
+type PrefixPlacement int
+
+type LevelCase int
+
+const (
+	LEVEL_UPPER LevelCase = iota
+	LEVEL_LOWER
+)
+
+const (
+	PREFIX_BEFORE_MESSAGE PrefixPlacement = iota
+	PREFIX_BEFORE_CALLER
+)
+
+type OverflowPolicy int32
+
+const (
+	BLOCK OverflowPolicy = iota
+	DROP
+)
+
 const (
-	DEBUG Level = iota
+	TRACE Level = iota
+	DEBUG
 	INFO
 	WARN
 	ERROR
@@ -21,9 +49,8 @@ const (
 	INVALID Level = -1
 )
 
-var logLevel = INFO
-
 var level_string = [...]string{
+	"TRACE",
 	"DEBUG",
 	"INFO",
 	"WARN",
@@ -32,328 +59,1664 @@ var level_string = [...]string{
 }
 
 type FileLog struct {
-	writer *os.File
+	writer io.Writer
+	buf    *bufio.Writer
 	path   string
+	size   int64
+	tty    bool
+	owned  bool
+	format Formatter // Overrides the logger formatter if set.
+}
+
+type syncer interface {
+	Sync() error
+}
+
+/* Implemented by outputs rendering messages themselves, like syslog. */
+type messageWriter interface {
+	writeMessage(l *Logger, o *FileLog, lines *lineCache) (n int, err error)
 }
 
 type Caller struct {
 	filename string
+	path     string
 	line     int
+	function string
 }
 
 type Message struct {
 	caller  Caller
 	message string
 	level   Level
+	time    time.Time
+	name    string
+	prefix  string
+	format  string
+	args    []interface{}
+	fields  []Field
+	stack   string
+	flushed chan byte
+	raw     []byte
+	color   bool
 }
 
-func SetLogLevel(level Level) {
-	logLevel = level
+var message_pool = sync.Pool{
+	New: func() interface{} {
+		return new(Message)
+	},
 }
 
-func NewFd(w *os.File) (fl *FileLog) {
-	return &FileLog{
+/* Messages taken from the pool by the logging functions are returned once written. */
+func release(m *Message) {
+	if m.flushed != nil {
+		return
+	}
+	*m = Message{}
+	message_pool.Put(m)
+}
+
+func (m *Message) Level() Level {
+	return m.level
+}
+
+func (m *Message) File() string {
+	return m.caller.filename
+}
+
+func (m *Message) Path() string {
+	return m.caller.path
+}
+
+func (m *Message) Line() int {
+	return m.caller.line
+}
+
+/* False when the caller was not captured, see SetShowCaller. */
+func (m *Message) hasCaller() bool {
+	return m.caller.path != ""
+}
+
+func (m *Message) Function() string {
+	return m.caller.function
+}
+
+func (m *Message) Text() string {
+	return m.message
+}
+
+func (m *Message) Time() time.Time {
+	return m.time
+}
+
+func (m *Message) Fields() []Field {
+	return m.fields
+}
+
+func (m *Message) Name() string {
+	return m.name
+}
+
+func (m *Message) Prefix() string {
+	return m.prefix
+}
+
+func (m *Message) Stack() string {
+	return m.stack
+}
+
+type Logger struct {
+	dropped       uint64 // Accessed atomically, keep 64-bit aligned.
+	seq           uint64 // Likewise.
+	showSeq       int32
+	overflow      int32
+	level         int32
+	sync          int32
+	stopped       int32
+	quiet         int32
+	hideCaller    int32
+	queueHigh     int32
+	flushEvery    int64
+	stackLevel    int32
+	printLevel    int32
+	showPID       int32
+	showGoroutine int32
+	out           *FileLog
+	outputs       []*FileLog
+	handlers      []Handler
+	filter        func(*Message) bool
+	transform     func(*Message)
+	redactors     []redactor
+	globalFields  []Field
+	console       *FileLog
+	consoleLevel  Level
+	prefix        string
+	timeFormat    string
+	indent        string
+	escapeNL      bool
+	placement     PrefixPlacement
+	precision     time.Duration
+	utc           bool
+	discard       bool
+	format        Formatter
+	fullPath      bool
+	trimPrefix    string
+	color         colorMode
+	maxSize       int64
+	interval      time.Duration
+	periodStart   time.Time
+	periodEnd     time.Time
+	maxBackups    int
+	maxAge        time.Duration
+	compress      bool
+	exit          func(int)
+	noExit        bool
+	fatalPanics   bool
+	filePerm      os.FileMode
+	dirPerm       os.FileMode
+	ctxKeys       []contextKey
+	levels        atomic.Value // map[string]Level, replaced on every change.
+	onError       func(error)
+	ring          *ring
+	byLevel       map[Level][]*FileLog
+	levelNames    []string
+	levelWidth    int
+	levelCase     LevelCase
+	sampling      int
+	last          *Message
+	repeats       int
+	suppressed    int
+	pruneLock     sync.Mutex
+	showFunc      bool
+	fullFunc      bool
+	queue         chan *Message
+	termsig       chan byte
+	termack       chan byte
+	quit_signal   chan byte
+	lock          sync.Mutex
+	daemonLock    sync.Mutex   // Serializes Start, Stop and Flush.
+	sendLock      sync.RWMutex // Read locked while enqueueing, so stop cannot miss a message.
+	has_daemon    bool         // Guarded by daemonLock.
+}
+
+func NewWriter(w io.Writer) (fl *FileLog) {
+	fl = &FileLog{
 		writer: w,
+		buf:    bufio.NewWriter(w),
 		path:   "",
 	}
+	if f, ok := w.(*os.File); ok {
+		fl.tty = isTerminal(f)
+	}
+	return
+}
+
+func NewFd(w *os.File) (fl *FileLog) {
+	return NewWriter(w)
 }
 
+/* Missing parent directories are created, with the modes set by SetFilePerm and SetDirPerm. */
 func NewFile(f string) (fl *FileLog, err error) {
-	w, err := os.OpenFile(f, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0660)
+	perm, dirPerm := std.perms()
+	return openFile(f, perm, dirPerm)
+}
+
+/* The path is made absolute, so rotation reopens the same file after a chdir. */
+func openFile(f string, perm, dirPerm os.FileMode) (fl *FileLog, err error) {
+	if abs, err := filepath.Abs(f); err == nil {
+		f = abs
+	}
+	if err = os.MkdirAll(filepath.Dir(f), dirPerm); err != nil {
+		return nil, err
+	}
+	w, err := os.OpenFile(f, os.O_WRONLY|os.O_APPEND|os.O_CREATE, perm)
 	if err != nil {
 		return nil, err
 	}
 	fl = NewFd(w)
 	fl.path = f
+	fl.owned = true
+	if st, err := w.Stat(); err == nil {
+		fl.size = st.Size()
+	}
 	return
 }
 
+/* Flushes, but only closes files opened by golog itself, never descriptors handed in by the user. */
+func (fl *FileLog) close() error {
+	fl.flush()
+	if !fl.owned {
+		return nil
+	}
+	if c, ok := fl.writer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
 func filename(path string) (file string) {
 	_, file = filepath.Split(path)
 	return
 }
 
-var queue = make(chan *Message, 32)
-var quit_signal = make(chan byte, 1)
+func funcname(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
 
-var logger = NewFd(os.Stderr)
-var termsig = make(chan byte, 1)
-var prefix = ""
-var lock sync.Mutex
-var has_daemon bool
+func New(w io.Writer) (l *Logger) {
+	l = &Logger{
+		out:         NewWriter(w),
+		level:       int32(INFO),
+		stackLevel:  int32(INVALID),
+		printLevel:  int32(INFO),
+		levelNames:  level_string[:],
+		prefix:      "",
+		timeFormat:  "Jan 2 15:04:05.000",
+		format:      TEXT,
+		queue:       make(chan *Message, 32),
+		termsig:     make(chan byte, 1),
+		termack:     make(chan byte),
+		quit_signal: make(chan byte, 1),
+		has_daemon:  true,
+		exit:        os.Exit,
+		filePerm:    0660,
+		dirPerm:     0770,
+	}
+	go l.daemon()
+	return
+}
 
-func daemon() {
-	has_daemon = true
-	for {
-		select {
-		case <-termsig:
-			return
-		case msg := <-queue:
-			lock.Lock()
-			fmt.Fprintf(logger.writer, "[%5s @ %s][%s:%d] %s%s\n", level_string[msg.level],
-				time.Now().Format("Jan 2 15:04:05.000"), msg.caller.filename, msg.caller.line, prefix, msg.message)
-			if msg.level == FATAL {
-				quit_signal <- '\x00'
+var std = New(os.Stderr)
+
+/* GOLOG_LEVEL sets the initial level of the default logger, SetLogLevel still overrides it. */
+func init() {
+	str := os.Getenv("GOLOG_LEVEL")
+	if str == "" {
+		return
+	}
+	if level := ToLevel(str); level != INVALID {
+		std.SetLogLevel(level)
+	} else {
+		std.Warnf("Invalid GOLOG_LEVEL %q, using %s", str, level_string[INFO])
+	}
+}
+
+func (l *Logger) write(msg *Message) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.writeLocked(msg)
+}
+
+/* Must be called with lock held. */
+func (l *Logger) writeLocked(msg *Message) {
+	/* A panicking formatter or writer must not take the daemon down. */
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "golog: panic while writing log message: %v\n", r)
+			if msg.flushed != nil {
+				close(msg.flushed)
 			}
-			lock.Unlock()
 		}
+	}()
+	if msg.flushed != nil {
+		/* Flush sentinel, everything ahead of it is already written. */
+		l.flushRepeats()
+		l.flush()
+		close(msg.flushed)
+		return
+	}
+	if l.discard {
+		return
+	}
+	if msg.raw != nil {
+		l.writeRaw(msg.raw)
+		return
+	}
+	if msg.args != nil {
+		msg.message = fmt.Sprintf(msg.format, msg.args...)
+		msg.format, msg.args = "", nil
+	}
+	/* The time was taken when the message was queued. */
+	if l.precision > 0 {
+		msg.time = msg.time.Truncate(l.precision)
+	}
+	if l.utc {
+		msg.time = msg.time.UTC()
+	}
+	msg.fields = l.addGlobalFields(msg.fields)
+	if l.hook(msg) && l.sample(msg) {
+		l.render(msg)
 	}
 }
 
-func SetPrefix(pre string) {
-	prefix = pre
+/* Must be called with lock held. */
+func (l *Logger) render(msg *Message) {
+	lines := lineCache{msg: msg}
+	var seen [8]*FileLog
+	done := seen[:0]
+	dests := l.levelOutputs(msg.level)
+	if dests == nil {
+		done = l.deliver(l.out, &lines, done)
+	}
+	/* A failing output must not keep the others from receiving the message. */
+	for _, o := range dests {
+		done = l.deliver(o, &lines, done)
+	}
+	for _, o := range l.outputs {
+		done = l.deliver(o, &lines, done)
+	}
+	if l.console != nil && msg.level >= l.consoleLevel {
+		l.deliver(l.console, &lines, done)
+	}
+	l.handle(msg)
+	if l.ring != nil {
+		l.ring.add(strings.TrimSuffix(string(lines.get(l, l.format, false)), "\n"))
+	}
 }
 
-func Open(f string) (err error) {
-	lock.Lock()
-	defer lock.Unlock()
-	fl, err := NewFile(f)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to open file %s: %s", f, err)
-		return err
-	} else {
-		logger = fl
-		Infof("Log ready.")
+/*
+ * Must be called with lock held. Writes the line to o unless an output in
+ * done already writes to the same writer, and returns done with o added.
+ * The main output gets its rollover check and size accounting wherever it
+ * appears.
+ */
+func (l *Logger) deliver(o *FileLog, lines *lineCache, done []*FileLog) []*FileLog {
+	for _, d := range done {
+		if sameOutput(d, o) {
+			return done
+		}
 	}
-	return nil
+	done = append(done, o)
+	if o != l.out {
+		l.emit(o, lines)
+		return done
+	}
+	l.checkRollover(int64(len(l.line(o, lines))), lines.msg.time)
+	/* The rollover may have replaced the main output. */
+	n, _ := l.emit(l.out, lines)
+	l.out.size += int64(n)
+	return done
 }
 
-func OpenFd(fd *os.File) {
-	logger = NewFd(fd)
+/* Writers of uncomparable types only match their own output. */
+func sameOutput(a, b *FileLog) bool {
+	if a == b {
+		return true
+	}
+	t := reflect.TypeOf(a.writer)
+	return t == reflect.TypeOf(b.writer) && t != nil && t.Comparable() && a.writer == b.writer
 }
 
-func init() {
-	go daemon()
+/* Must be called with lock held. */
+func (l *Logger) emit(o *FileLog, lines *lineCache) (n int, err error) {
+	if mw, ok := o.writer.(messageWriter); ok {
+		/* Raw writes go through the buffer, they must not fall behind. */
+		if o.buf.Buffered() > 0 {
+			if err := o.flush(); err != nil && l.onError != nil {
+				l.onError(err)
+			}
+		}
+		n, err = mw.writeMessage(l, o, lines)
+	} else {
+		n, err = l.writeLine(o, l.line(o, lines))
+	}
+	if err != nil && l.onError != nil {
+		l.onError(err)
+	}
+	return
 }
 
-func Start() {
-	if !has_daemon {
-		go daemon()
+/* Must be called with lock held. */
+func (l *Logger) writeLine(o *FileLog, line []byte) (int, error) {
+	/*
+	 * Never split a line across two writes, so appends from other
+	 * processes to the same file can only land between whole lines.
+	 */
+	if len(line) > o.buf.Available() && o.buf.Buffered() > 0 {
+		if err := o.flush(); err != nil && l.onError != nil {
+			l.onError(err)
+		}
 	}
+	return o.buf.Write(line)
 }
 
-func Stop() {
-	if has_daemon {
-		termsig <- '\x00'
+func (l *Logger) process(msg *Message) {
+	l.write(msg)
+	fatal := msg.level == FATAL
+	release(msg)
+	if fatal {
+		/* Everything enqueued before the fatal call must be persisted before exiting. */
+		l.drain()
+		l.flushOutputs()
+		l.quit_signal <- '\x00'
+	}
+}
+
+const batch_size = 64
+
+/*
+ * Writes msg and whatever else is already queued, up to batch_size messages,
+ * under a single lock acquisition. A FATAL message ends the batch and is
+ * processed on its own, as it drains the queue itself.
+ */
+func (l *Logger) processBatch(msg *Message) {
+	l.lock.Lock()
+	for i := 1; ; i++ {
+		if msg.level == FATAL {
+			l.lock.Unlock()
+			l.process(msg)
+			return
+		}
+		l.writeLocked(msg)
+		release(msg)
+		if i == batch_size {
+			break
+		}
+		select {
+		case msg = <-l.queue:
+			continue
+		default:
+		}
+		break
 	}
-	has_daemon = false
+	l.lock.Unlock()
 }
 
-func Fatal(msg string) {
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "<unknown>"
-		line = 0
+func (l *Logger) drain() {
+	for {
+		select {
+		case msg := <-l.queue:
+			l.processBatch(msg)
+		default:
+			return
+		}
 	}
-	queue <- &Message{
-		caller: Caller{
-			filename: filename(file),
-			line:     line,
-		},
-		message: msg,
-		level:   FATAL,
+}
+
+func (l *Logger) daemon() {
+	var tick <-chan time.Time
+	for {
+		select {
+		case <-l.termsig:
+			/* Drain whatever is still queued before acknowledging. */
+			l.drain()
+			l.flushOutputs()
+			l.termack <- '\x00'
+			return
+		case msg := <-l.queue:
+			l.processBatch(msg)
+			if d := l.loadFlushInterval(); d > 0 {
+				if tick == nil {
+					tick = time.After(d)
+				}
+			} else if len(l.queue) == 0 {
+				l.flushOutputs()
+			}
+		case <-tick:
+			tick = nil
+			l.flushOutputs()
+		}
 	}
-	/* Wait for flushing logs. */
-	<-quit_signal
-	os.Exit(1)
 }
 
-func Fatalf(format string, a ...interface{}) {
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "<unknown>"
-		line = 0
-	}
-	queue <- &Message{
-		caller: Caller{
-			filename: filename(file),
-			line:     line,
-		},
-		message: fmt.Sprintf(format, a...),
-		level:   FATAL,
-	}
-	/* Wait for flushing logs. */
-	<-quit_signal
-	os.Exit(1)
-}
-
-func Error(msg string) {
-	if logLevel > ERROR {
+/*
+ * FATAL messages are never dropped, Fatal waits for them to be written.
+ *
+ * Ordering: every message goes through this single queue and one daemon, so
+ * messages logged by one goroutine are written in call order. Messages from
+ * different goroutines may interleave, but each one is rendered and written
+ * whole under the logger lock and never torn by another message.
+ */
+func (l *Logger) enqueue(msg *Message) {
+	l.sendLock.RLock()
+	if atomic.LoadInt32(&l.sync) != 0 || atomic.LoadInt32(&l.stopped) != 0 {
+		l.sendLock.RUnlock()
+		l.process(msg)
+		l.flushOutputs()
 		return
 	}
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "<unknown>"
-		line = 0
+	defer l.sendLock.RUnlock()
+	if msg.level == FATAL || OverflowPolicy(atomic.LoadInt32(&l.overflow)) != DROP {
+		l.queue <- msg
+		l.markDepth()
+		return
 	}
-	queue <- &Message{
-		caller: Caller{
-			filename: filename(file),
-			line:     line,
-		},
-		message: msg,
-		level:   ERROR,
+	select {
+	case l.queue <- msg:
+		l.markDepth()
+	default:
+		atomic.AddUint64(&l.dropped, 1)
+		release(msg)
 	}
 }
 
-func Errorf(format string, a ...interface{}) {
-	if logLevel > ERROR {
-		return
-	}
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "<unknown>"
-		line = 0
+/* Records the queue depth seen right after enqueueing, for QueueHighWater. */
+func (l *Logger) markDepth() {
+	n := int32(len(l.queue))
+	for {
+		high := atomic.LoadInt32(&l.queueHigh)
+		if n <= high || atomic.CompareAndSwapInt32(&l.queueHigh, high, n) {
+			return
+		}
 	}
-	queue <- &Message{
-		caller: Caller{
-			filename: filename(file),
-			line:     line,
-		},
-		message: fmt.Sprintf(format, a...),
-		level:   ERROR,
+}
+
+/* Messages waiting for the daemon. QueueLen, QueueCap and QueueHighWater are safe to poll concurrently. */
+func (l *Logger) QueueLen() int {
+	return len(l.queue)
+}
+
+func (l *Logger) QueueCap() int {
+	return cap(l.queue)
+}
+
+/* The deepest the queue has been since the logger was created. */
+func (l *Logger) QueueHighWater() int {
+	return int(atomic.LoadInt32(&l.queueHigh))
+}
+
+/* In sync mode messages are written by the logging call itself, bypassing the queue. */
+func (l *Logger) SetSync(sync bool) {
+	if sync {
+		/* Keep ordering with whatever is still queued. */
+		l.Flush()
+		atomic.StoreInt32(&l.sync, 1)
+	} else {
+		atomic.StoreInt32(&l.sync, 0)
 	}
 }
 
-func Warn(msg string) {
-	if logLevel > WARN {
-		return
+func (l *Logger) SetOverflowPolicy(policy OverflowPolicy) {
+	atomic.StoreInt32(&l.overflow, int32(policy))
+}
+
+func (l *Logger) DroppedCount() uint64 {
+	return atomic.LoadUint64(&l.dropped)
+}
+
+/*
+ * Resolved callers by program counter. A call site only ever has one, so the
+ * costly lookup of file, line and function happens once per site.
+ */
+var caller_cache sync.Map
+
+func caller(skip int) Caller {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return Caller{filename: "<unknown>", path: "<unknown>", function: "<unknown>"}
+	}
+	if c, ok := caller_cache.Load(pcs[0]); ok {
+		return c.(Caller)
 	}
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "<unknown>"
-		line = 0
+	frame, _ := runtime.CallersFrames(pcs[:]).Next()
+	c := Caller{
+		filename: filename(frame.File),
+		path:     frame.File,
+		line:     frame.Line,
+		function: frame.Function,
 	}
-	queue <- &Message{
-		caller: Caller{
-			filename: filename(file),
-			line:     line,
-		},
-		message: msg,
-		level:   WARN,
+	if c.function == "" {
+		c.function = "<unknown>"
 	}
+	caller_cache.Store(pcs[0], c)
+	return c
 }
 
-func Warnf(format string, a ...interface{}) {
-	if logLevel > WARN {
+/*
+ * All logging functions end up here. skip counts frames above the public
+ * function calling log, whose own caller is reported with skip 0.
+ *
+ * FATAL messages honor the level like any other, but the process still exits
+ * afterwards unless disabled with SetFatalExit.
+ */
+func (l *Logger) log(level Level, skip int, msg string, e *Entry) {
+	l.post(level, skip+1, msg, nil, e, nil)
+}
+
+/*
+ * Like log, with stack rendering the stack slot of the message regardless of
+ * SetStackLevel. With args, msg is a format string rendered later by the daemon.
+ */
+func (l *Logger) post(level Level, skip int, msg string, args []interface{}, e *Entry, stack func() string) {
+	if !l.send(level, skip+1, msg, args, e, stack) {
+		if level == FATAL {
+			l.fatalExit()
+		}
 		return
 	}
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "<unknown>"
-		line = 0
-	}
-	queue <- &Message{
-		caller: Caller{
-			filename: filename(file),
-			line:     line,
-		},
-		message: fmt.Sprintf(format, a...),
-		level:   WARN,
+	if level == FATAL {
+		/* Wait for flushing logs. */
+		<-l.quit_signal
+		l.fatalExit()
 	}
 }
 
-func Info(msg string) {
-	if logLevel > INFO {
-		return
+/* Queues the message unless its level is disabled. FATAL handling is left to the caller. */
+func (l *Logger) send(level Level, skip int, msg string, args []interface{}, e *Entry, stack func() string) bool {
+	if l.levelFor(e) > level {
+		return false
 	}
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "<unknown>"
-		line = 0
+	m := message_pool.Get().(*Message)
+	m.time = time.Now()
+	if atomic.LoadInt32(&l.hideCaller) == 0 {
+		m.caller = caller(skip + 2)
 	}
-	queue <- &Message{
-		caller: Caller{
-			filename: filename(file),
-			line:     line,
-		},
-		message: msg,
-		level:   INFO,
+	if args != nil {
+		m.format, m.args = msg, args
+	} else {
+		m.message = msg
+	}
+	m.level = level
+	if e != nil {
+		m.fields = snapshot(e.fields)
+		m.prefix = e.prefix
+		m.name = e.name
+	}
+	m.fields = l.idFields(m.fields)
+	if stack == nil && l.wantStack(level) {
+		stack = stackTrace
+	}
+	if stack != nil {
+		m.stack = stack()
 	}
+	l.enqueue(m)
+	return true
 }
 
-func Infof(format string, a ...interface{}) {
-	if logLevel > INFO {
+/*
+ * Like log, but only formats the message once the level check passed.
+ * Formatting is left to the daemon when no argument can change after the
+ * call returns.
+ */
+func (l *Logger) logf(level Level, skip int, format string, a []interface{}, e *Entry) {
+	if l.levelFor(e) > level {
+		if level == FATAL {
+			l.fatalExit()
+		}
 		return
 	}
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "<unknown>"
-		line = 0
+	if len(a) == 0 {
+		/* Sprintf still has to turn %% into %. */
+		if strings.IndexByte(format, '%') >= 0 {
+			format = fmt.Sprintf(format, a...)
+		}
+		l.post(level, skip+1, format, nil, e, nil)
+		return
 	}
-	queue <- &Message{
-		caller: Caller{
-			filename: filename(file),
-			line:     line,
-		},
-		message: fmt.Sprintf(format, a...),
-		level:   INFO,
+	for _, v := range a {
+		if !immutable(v) {
+			l.post(level, skip+1, fmt.Sprintf(format, a...), nil, e, nil)
+			return
+		}
 	}
+	l.post(level, skip+1, format, append([]interface{}(nil), a...), e, nil)
 }
 
-func Debug(msg string) {
-	if logLevel > DEBUG {
-		return
+/* The panic value of Fatal calls after SetFatalPanics(true). */
+var ErrFatal = errors.New("golog: fatal message logged")
+
+func (l *Logger) fatalExit() {
+	l.lock.Lock()
+	exit, noExit, panics := l.exit, l.noExit, l.fatalPanics
+	l.lock.Unlock()
+	if panics {
+		panic(ErrFatal)
 	}
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "<unknown>"
-		line = 0
+	if !noExit {
+		exit(1)
+	}
+}
+
+/*
+ * With panics set, Fatal panics with ErrFatal once the message is written
+ * instead of exiting, so tests can recover from it.
+ */
+func (l *Logger) SetFatalPanics(panics bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.fatalPanics = panics
+}
+
+func (l *Logger) SetFatalExit(exit bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.noExit = !exit
+}
+
+func (l *Logger) SetLogLevel(level Level) {
+	atomic.StoreInt32(&l.level, int32(level))
+}
+
+func (l *Logger) loadLevel() Level {
+	return Level(atomic.LoadInt32(&l.level))
+}
+
+func (l *Logger) GetLogLevel() Level {
+	return l.loadLevel()
+}
+
+func (l *Logger) IsEnabled(level Level) bool {
+	return level >= l.loadLevel()
+}
+
+func (l *Logger) SetPrefix(pre string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.prefix = pre
+}
+
+/*
+ * Where the text formatter puts the logger and entry prefixes, right before
+ * the message by default. Other formatters prepend them to the message.
+ */
+func (l *Logger) SetPrefixPlacement(placement PrefixPlacement) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.placement = placement
+}
+
+/* Prefix and TimeFormat take the lock, so a Formatter must not call them. */
+func (l *Logger) Prefix() string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.prefix
+}
+
+func (l *Logger) TimeFormat() string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.timeFormat
+}
+
+func (l *Logger) SetFullPath(full bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.fullPath = full
+}
+
+/*
+ * Caller paths starting with prefix are shown relative to it, like
+ * "server/handler.go" for prefix "/home/ci/app/". Other paths are shown as before.
+ */
+func (l *Logger) SetTrimPrefix(prefix string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.trimPrefix = prefix
+}
+
+/* Must be called with lock held. */
+func (l *Logger) file(msg *Message) string {
+	if l.trimPrefix != "" && strings.HasPrefix(msg.caller.path, l.trimPrefix) {
+		return msg.caller.path[len(l.trimPrefix):]
 	}
-	queue <- &Message{
-		caller: Caller{
-			filename: filename(file),
-			line:     line,
-		},
-		message: msg,
-		level:   DEBUG,
+	if l.fullPath {
+		return msg.caller.path
 	}
+	return msg.caller.filename
 }
 
-func Debugf(format string, a ...interface{}) {
-	if logLevel > DEBUG {
-		return
+func (l *Logger) SetShowFunc(show bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.showFunc = show
+}
+
+/*
+ * Disabling skips looking up the caller of every logging call, which is
+ * relatively costly, and leaves file, line and function out of the output.
+ */
+func (l *Logger) SetShowCaller(show bool) {
+	if show {
+		atomic.StoreInt32(&l.hideCaller, 0)
+	} else {
+		atomic.StoreInt32(&l.hideCaller, 1)
 	}
-	_, file, line, ok := runtime.Caller(1)
-	if !ok {
-		file = "<unknown>"
-		line = 0
+}
+
+/* By default only the last path element is printed, e.g. main.handler. */
+func (l *Logger) SetFullFuncName(full bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.fullFunc = full
+}
+
+/* Must be called with lock held. Returns empty string when disabled. */
+func (l *Logger) function(msg *Message) string {
+	if !l.showFunc {
+		return ""
 	}
-	queue <- &Message{
-		caller: Caller{
-			filename: filename(file),
-			line:     line,
-		},
-		message: fmt.Sprintf(format, a...),
-		level:   DEBUG,
+	if l.fullFunc {
+		return msg.caller.function
 	}
+	return funcname(msg.caller.function)
 }
 
-func Rotate() (err error) {
-	lock.Lock()
-	defer lock.Unlock()
-	logger.writer.Sync() // Ignore error here.
-	if logger.path != "" {
-		newfd, err := os.OpenFile(logger.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0660)
-		if err != nil {
-			Errorf("Reopen log file %s: %s", logger.path, err)
-			return err
-		} else {
-			Infof("Reopened log file %s", logger.path)
-			newlog := NewFd(newfd)
-			newlog.path = logger.path
-			logger = newlog
-		}
+func (l *Logger) SetFormat(f Formatter) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.format = f
+}
+
+/*
+ * An empty layout disables the timestamp entirely. The default shows
+ * milliseconds, use ".000000" or ".000000000" for micro or nanoseconds.
+ */
+func (l *Logger) SetTimeFormat(layout string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.timeFormat = layout
+}
+
+/* Timestamps are in local time unless utc is set. */
+func (l *Logger) SetUTC(utc bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.utc = utc
+}
+
+/* Timestamps are truncated to a multiple of d, zero keeps them exact. */
+func (l *Logger) SetTimePrecision(d time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.precision = d
+}
+
+var newline_escaper = strings.NewReplacer("\n", `\n`, "\r", `\r`)
+
+/*
+ * The text formatter then replaces line breaks with \n and \r, so every
+ * message stays on one physical line: prefix, name and message, as well as
+ * stack traces and error details, which follow the fields. JSON and logfmt
+ * quoting already keep messages on one line and are not affected.
+ */
+func (l *Logger) SetEscapeNewlines(escape bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.escapeNL = escape
+}
+
+/*
+ * Continuation lines of a multi-line message are prefixed with indent by the
+ * text formatter, so they are easy to tell apart from the next message.
+ */
+func (l *Logger) SetMultilineIndent(indent string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.indent = indent
+}
+
+/* Called after a FATAL message is written, defaults to os.Exit. Fatal returns if it does. */
+func (l *Logger) SetExitFunc(exit func(int)) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.exit = exit
+}
+
+/*
+ * The handler is called from the daemon with the logger lock held whenever an
+ * output fails. It must not log through the same logger synchronously.
+ */
+func (l *Logger) SetErrorHandler(handler func(error)) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.onError = handler
+}
+
+/* Disabling suppresses the INFO lines golog logs about itself on Open and Rotate. Errors are still logged. */
+func (l *Logger) SetVerboseInternal(verbose bool) {
+	if verbose {
+		atomic.StoreInt32(&l.quiet, 0)
+	} else {
+		atomic.StoreInt32(&l.quiet, 1)
 	}
-	return nil
 }
 
-func ToLevel(str string) (level Level) {
-	str = strings.ToUpper(str)
-	for l, s := range level_string {
-		if str == s {
-			return Level(l)
-		}
+/* Log files are created with perm, 0660 by default, subject to the umask. */
+func (l *Logger) SetFilePerm(perm os.FileMode) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.filePerm = perm
+}
+
+/* Alias of SetFilePerm. */
+func (l *Logger) SetFileMode(mode os.FileMode) {
+	l.SetFilePerm(mode)
+}
+
+/* Missing parent directories of log files are created with perm, 0770 by default. */
+func (l *Logger) SetDirPerm(perm os.FileMode) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.dirPerm = perm
+}
+
+func (l *Logger) perms() (perm, dirPerm os.FileMode) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.filePerm, l.dirPerm
+}
+
+/* Must be called with lock held. Opens path with the configured permissions. */
+func (l *Logger) openFile(path string) (*FileLog, error) {
+	return openFile(path, l.filePerm, l.dirPerm)
+}
+
+func (l *Logger) Open(f string) (err error) {
+	perm, dirPerm := l.perms()
+	fl, err := openFile(f, perm, dirPerm)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open file %s: %s", f, err)
+		return err
 	}
-	return Level(-1)
+	l.lock.Lock()
+	l.out.close()
+	l.out = fl
+	l.periodEnd = time.Time{}
+	l.lock.Unlock()
+	/* Logged without the lock held, sync mode writes inline. */
+	if atomic.LoadInt32(&l.quiet) == 0 {
+		l.Infof("Log ready.")
+	}
+	return nil
+}
+
+/* A nil fd, like the result of a failed os.OpenFile, is ignored with a warning. */
+func (l *Logger) OpenFd(fd *os.File) {
+	if fd == nil {
+		l.Warnf("OpenFd called with a nil file, keeping the current output")
+		return
+	}
+	l.OpenWriter(fd)
+}
+
+/* A nil interface, or a nil *os.File as returned by a failed os.OpenFile. */
+func nilWriter(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return w == nil || ok && f == nil
+}
+
+func (l *Logger) OpenWriter(w io.Writer) {
+	if nilWriter(w) {
+		l.Warnf("OpenWriter called with a nil writer, keeping the current output")
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.out.close()
+	l.out = NewWriter(w)
+}
+
+/*
+ * Like OpenWriter, but returns the previous main output for restoring it
+ * later. It is flushed and left open, a file opened by Open becomes the
+ * caller's to close.
+ */
+func (l *Logger) SetOutput(w io.Writer) (prev io.Writer) {
+	if nilWriter(w) {
+		l.Warnf("SetOutput called with a nil writer, keeping the current output")
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		return l.out.writer
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.out.flush()
+	prev = l.out.writer
+	l.out = NewWriter(w)
+	l.periodEnd = time.Time{}
+	return
+}
+
+/*
+ * While discarding, the daemon keeps draining the queue but formats and
+ * writes nothing. Outputs are kept, so SetDiscard(false) resumes logging.
+ */
+func (l *Logger) SetDiscard(discard bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.discard = discard
+}
+
+func (l *Logger) Discard() {
+	l.SetDiscard(true)
+}
+
+/* Additional outputs receive every message written to the main output. */
+func (l *Logger) AddOutput(w io.Writer) {
+	if nilWriter(w) {
+		l.Warnf("AddOutput called with a nil writer, ignoring it")
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.outputs = append(l.outputs, NewWriter(w))
+}
+
+func (l *Logger) RemoveOutput(w io.Writer) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for i, o := range l.outputs {
+		if o.writer == w {
+			o.flush()
+			l.outputs = append(l.outputs[:i], l.outputs[i+1:]...)
+			return
+		}
+	}
+}
+
+/*
+ * Must be called before logging starts, messages enqueued concurrently may be lost.
+ * Shrinking is not supported, sizes not larger than the current capacity are ignored.
+ */
+func (l *Logger) SetQueueSize(n int) {
+	if n <= cap(l.queue) {
+		return
+	}
+	l.daemonLock.Lock()
+	defer l.daemonLock.Unlock()
+	running := l.has_daemon
+	l.stop()
+	l.queue = make(chan *Message, n)
+	if running {
+		l.start()
+	}
+}
+
+/*
+ * Start and Stop are idempotent and safe to call concurrently, at most one
+ * daemon runs. A stopped logger writes messages synchronously, so logging
+ * keeps working until Start resumes the daemon.
+ */
+func (l *Logger) Start() {
+	l.daemonLock.Lock()
+	defer l.daemonLock.Unlock()
+	l.start()
+}
+
+func (l *Logger) Stop() {
+	l.daemonLock.Lock()
+	defer l.daemonLock.Unlock()
+	l.stop()
+}
+
+/* Must be called with daemonLock held. */
+func (l *Logger) start() {
+	if !l.has_daemon {
+		l.has_daemon = true
+		atomic.StoreInt32(&l.stopped, 0)
+		go l.daemon()
+	}
+}
+
+/* Must be called with daemonLock held. */
+func (l *Logger) stop() {
+	if l.has_daemon {
+		/* Waits for messages being enqueued, later ones see stopped. */
+		l.sendLock.Lock()
+		l.termsig <- '\x00'
+		<-l.termack
+		/* Until the next Start, messages are written by the logging call. */
+		atomic.StoreInt32(&l.stopped, 1)
+		l.sendLock.Unlock()
+		l.drain()
+		l.lock.Lock()
+		l.flushRepeats()
+		l.flush()
+		l.lock.Unlock()
+	}
+	l.has_daemon = false
+}
+
+/*
+ * Blocks until every message queued before the call is written. Holds
+ * daemonLock, so the daemon cannot stop with the sentinel still queued.
+ */
+func (l *Logger) Flush() {
+	l.daemonLock.Lock()
+	defer l.daemonLock.Unlock()
+	if !l.has_daemon {
+		return
+	}
+	flushed := make(chan byte)
+	l.queue <- &Message{flushed: flushed}
+	<-flushed
+}
+
+/* Log and Logf pick the level at runtime, FATAL still exits. */
+func (l *Logger) Log(level Level, msg string) {
+	l.log(level, 0, msg, nil)
+}
+
+func (l *Logger) Logf(level Level, format string, a ...interface{}) {
+	l.logf(level, 0, format, a, nil)
+}
+
+func (l *Logger) Fatal(msg string, fields ...Field) {
+	l.log(FATAL, 0, msg, l.with(fields))
+}
+
+func (l *Logger) Fatalf(format string, a ...interface{}) {
+	l.logf(FATAL, 0, format, a, nil)
+}
+
+func (l *Logger) Error(msg string, fields ...Field) {
+	l.log(ERROR, 0, msg, l.with(fields))
+}
+
+func (l *Logger) Errorf(format string, a ...interface{}) {
+	l.logf(ERROR, 0, format, a, nil)
+}
+
+func (l *Logger) Warn(msg string, fields ...Field) {
+	l.log(WARN, 0, msg, l.with(fields))
+}
+
+func (l *Logger) Warnf(format string, a ...interface{}) {
+	l.logf(WARN, 0, format, a, nil)
+}
+
+func (l *Logger) Info(msg string, fields ...Field) {
+	l.log(INFO, 0, msg, l.with(fields))
+}
+
+func (l *Logger) Infof(format string, a ...interface{}) {
+	l.logf(INFO, 0, format, a, nil)
+}
+
+func (l *Logger) Debug(msg string, fields ...Field) {
+	l.log(DEBUG, 0, msg, l.with(fields))
+}
+
+func (l *Logger) Debugf(format string, a ...interface{}) {
+	l.logf(DEBUG, 0, format, a, nil)
+}
+
+func (l *Logger) Trace(msg string, fields ...Field) {
+	l.log(TRACE, 0, msg, l.with(fields))
+}
+
+func (l *Logger) Tracef(format string, a ...interface{}) {
+	l.logf(TRACE, 0, format, a, nil)
+}
+
+func (l *Logger) FatalfDepth(skip int, format string, a ...interface{}) {
+	l.logf(FATAL, skip, format, a, nil)
+}
+
+func (l *Logger) ErrorfDepth(skip int, format string, a ...interface{}) {
+	l.logf(ERROR, skip, format, a, nil)
+}
+
+func (l *Logger) WarnfDepth(skip int, format string, a ...interface{}) {
+	l.logf(WARN, skip, format, a, nil)
+}
+
+func (l *Logger) InfofDepth(skip int, format string, a ...interface{}) {
+	l.logf(INFO, skip, format, a, nil)
+}
+
+func (l *Logger) DebugfDepth(skip int, format string, a ...interface{}) {
+	l.logf(DEBUG, skip, format, a, nil)
+}
+
+func (l *Logger) TracefDepth(skip int, format string, a ...interface{}) {
+	l.logf(TRACE, skip, format, a, nil)
+}
+
+/* Implemented by writers doing their own rotation, like lumberjack.Logger. */
+type rotator interface {
+	Rotate() error
+}
+
+/*
+ * Reopens the file opened by Open, for external rotation. A main output
+ * implementing Rotate() error is asked to rotate itself instead.
+ */
+func (l *Logger) Rotate() (err error) {
+	l.lock.Lock()
+	l.flush()
+	if r, ok := l.out.writer.(rotator); ok {
+		err = r.Rotate()
+		l.lock.Unlock()
+		if err != nil {
+			l.Errorf("Rotate log writer: %s", err)
+		}
+		return err
+	}
+	if s, ok := l.out.writer.(syncer); ok {
+		s.Sync() // Ignore error here.
+	}
+	path := l.out.path
+	if path == "" {
+		l.lock.Unlock()
+		return nil
+	}
+	newlog, err := l.openFile(path)
+	if err == nil {
+		l.reopened(newlog)
+	}
+	l.lock.Unlock()
+	if err != nil {
+		l.Errorf("Reopen log file %s: %s", path, err)
+		return err
+	}
+	if atomic.LoadInt32(&l.quiet) == 0 {
+		l.Infof("Reopened log file %s", path)
+	}
+	return nil
+}
+
+func SetLogLevel(level Level) {
+	std.SetLogLevel(level)
+}
+
+func GetLogLevel() Level {
+	return std.GetLogLevel()
+}
+
+func IsEnabled(level Level) bool {
+	return std.IsEnabled(level)
+}
+
+func SetPrefix(pre string) {
+	std.SetPrefix(pre)
+}
+
+func SetOutput(w io.Writer) io.Writer {
+	return std.SetOutput(w)
+}
+
+func SetVerboseInternal(verbose bool) {
+	std.SetVerboseInternal(verbose)
+}
+
+func SetDiscard(discard bool) {
+	std.SetDiscard(discard)
+}
+
+func Discard() {
+	std.Discard()
+}
+
+func SetUTC(utc bool) {
+	std.SetUTC(utc)
+}
+
+func SetTimePrecision(d time.Duration) {
+	std.SetTimePrecision(d)
+}
+
+func SetLevelCase(c LevelCase) {
+	std.SetLevelCase(c)
+}
+
+func SetPrefixPlacement(placement PrefixPlacement) {
+	std.SetPrefixPlacement(placement)
+}
+
+func SetEscapeNewlines(escape bool) {
+	std.SetEscapeNewlines(escape)
+}
+
+func SetMultilineIndent(indent string) {
+	std.SetMultilineIndent(indent)
+}
+
+/* An empty layout disables the timestamp entirely. */
+func SetTimeFormat(layout string) {
+	std.SetTimeFormat(layout)
+}
+
+func SetFullPath(full bool) {
+	std.SetFullPath(full)
+}
+
+func SetShowCaller(show bool) {
+	std.SetShowCaller(show)
+}
+
+func SetTrimPrefix(prefix string) {
+	std.SetTrimPrefix(prefix)
+}
+
+func SetShowFunc(show bool) {
+	std.SetShowFunc(show)
+}
+
+func SetFullFuncName(full bool) {
+	std.SetFullFuncName(full)
+}
+
+func SetFormat(f Formatter) {
+	std.SetFormat(f)
+}
+
+func SetExitFunc(exit func(int)) {
+	std.SetExitFunc(exit)
+}
+
+func SetColor(color bool) {
+	std.SetColor(color)
+}
+
+func Named(name string) *Entry {
+	return std.Named(name)
+}
+
+func SetLevelFor(name string, level Level) {
+	std.SetLevelFor(name, level)
+}
+
+func SetLevelOutput(level Level, w ...io.Writer) {
+	std.SetLevelOutput(level, w...)
+}
+
+func WithPrefix(prefix string) *Entry {
+	return std.WithPrefix(prefix)
+}
+
+func WithFields(fields map[string]interface{}) *Entry {
+	return std.WithFields(fields)
+}
+
+func WithError(err error) *Entry {
+	return std.WithError(err)
+}
+
+func SetFilePerm(perm os.FileMode) {
+	std.SetFilePerm(perm)
+}
+
+func SetFileMode(mode os.FileMode) {
+	std.SetFileMode(mode)
+}
+
+func SetDirPerm(perm os.FileMode) {
+	std.SetDirPerm(perm)
+}
+
+func SetFatalPanics(panics bool) {
+	std.SetFatalPanics(panics)
+}
+
+func SetFatalExit(exit bool) {
+	std.SetFatalExit(exit)
+}
+
+func SetErrorHandler(handler func(error)) {
+	std.SetErrorHandler(handler)
+}
+
+func Open(f string) (err error) {
+	return std.Open(f)
+}
+
+func OpenFd(fd *os.File) {
+	std.OpenFd(fd)
+}
+
+func OpenWriter(w io.Writer) {
+	std.OpenWriter(w)
+}
+
+func AddOutput(w io.Writer) {
+	std.AddOutput(w)
+}
+
+func RemoveOutput(w io.Writer) {
+	std.RemoveOutput(w)
+}
+
+func SetQueueSize(n int) {
+	std.SetQueueSize(n)
+}
+
+func SetFlushInterval(d time.Duration) {
+	std.SetFlushInterval(d)
+}
+
+func SetSync(sync bool) {
+	std.SetSync(sync)
+}
+
+func SetOverflowPolicy(policy OverflowPolicy) {
+	std.SetOverflowPolicy(policy)
+}
+
+func DroppedCount() uint64 {
+	return std.DroppedCount()
+}
+
+func QueueLen() int {
+	return std.QueueLen()
+}
+
+func QueueCap() int {
+	return std.QueueCap()
+}
+
+func QueueHighWater() int {
+	return std.QueueHighWater()
+}
+
+func SetSampling(n int) {
+	std.SetSampling(n)
+}
+
+func Start() {
+	std.Start()
+}
+
+func Stop() {
+	std.Stop()
+}
+
+func Flush() {
+	std.Flush()
+}
+
+func Log(level Level, msg string) {
+	std.log(level, 0, msg, nil)
+}
+
+func Logf(level Level, format string, a ...interface{}) {
+	std.logf(level, 0, format, a, nil)
+}
+
+func Fatal(msg string, fields ...Field) {
+	std.log(FATAL, 0, msg, std.with(fields))
+}
+
+func Fatalf(format string, a ...interface{}) {
+	std.logf(FATAL, 0, format, a, nil)
+}
+
+func Error(msg string, fields ...Field) {
+	std.log(ERROR, 0, msg, std.with(fields))
+}
+
+func Errorf(format string, a ...interface{}) {
+	std.logf(ERROR, 0, format, a, nil)
+}
+
+func Warn(msg string, fields ...Field) {
+	std.log(WARN, 0, msg, std.with(fields))
+}
+
+func Warnf(format string, a ...interface{}) {
+	std.logf(WARN, 0, format, a, nil)
+}
+
+func Info(msg string, fields ...Field) {
+	std.log(INFO, 0, msg, std.with(fields))
+}
+
+func Infof(format string, a ...interface{}) {
+	std.logf(INFO, 0, format, a, nil)
+}
+
+func Debug(msg string, fields ...Field) {
+	std.log(DEBUG, 0, msg, std.with(fields))
+}
+
+func Debugf(format string, a ...interface{}) {
+	std.logf(DEBUG, 0, format, a, nil)
+}
+
+func Trace(msg string, fields ...Field) {
+	std.log(TRACE, 0, msg, std.with(fields))
+}
+
+func Tracef(format string, a ...interface{}) {
+	std.logf(TRACE, 0, format, a, nil)
+}
+
+func FatalfDepth(skip int, format string, a ...interface{}) {
+	std.logf(FATAL, skip, format, a, nil)
+}
+
+func ErrorfDepth(skip int, format string, a ...interface{}) {
+	std.logf(ERROR, skip, format, a, nil)
+}
+
+func WarnfDepth(skip int, format string, a ...interface{}) {
+	std.logf(WARN, skip, format, a, nil)
+}
+
+func InfofDepth(skip int, format string, a ...interface{}) {
+	std.logf(INFO, skip, format, a, nil)
+}
+
+func DebugfDepth(skip int, format string, a ...interface{}) {
+	std.logf(DEBUG, skip, format, a, nil)
+}
+
+func TracefDepth(skip int, format string, a ...interface{}) {
+	std.logf(TRACE, skip, format, a, nil)
+}
+
+func SetMaxSize(bytes int64) {
+	std.SetMaxSize(bytes)
+}
+
+func SetRotateInterval(d time.Duration) {
+	std.SetRotateInterval(d)
+}
+
+func SetMaxBackups(n int) {
+	std.SetMaxBackups(n)
+}
+
+func SetMaxAge(d time.Duration) {
+	std.SetMaxAge(d)
+}
+
+func SetCompress(compress bool) {
+	std.SetCompress(compress)
+}
+
+func Rotate() (err error) {
+	return std.Rotate()
+}
+
+func (l Level) String() string {
+	if l >= 0 && int(l) < len(level_string) {
+		return level_string[l]
+	}
+	if l == INVALID {
+		return "INVALID"
+	}
+	return "Level(" + strconv.Itoa(int(l)) + ")"
+}
+
+/* Must be called with lock held. Unlike String, unknown levels are printed as their bare number. */
+func (l *Logger) levelName(level Level) string {
+	if level >= 0 && int(level) < len(l.levelNames) {
+		if l.levelCase == LEVEL_LOWER {
+			return strings.ToLower(l.levelNames[level])
+		}
+		return l.levelNames[level]
+	}
+	return strconv.Itoa(int(level))
+}
+
+/*
+ * LEVEL_LOWER prints level names in lower case, as expected by some
+ * ingestors. Names set with SetLevelNames are lowered too.
+ */
+func (l *Logger) SetLevelCase(c LevelCase) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.levelCase = c
+}
+
+/* names are indexed by level, TRACE first, and must cover every level. */
+func (l *Logger) SetLevelNames(names []string) error {
+	if len(names) != len(level_string) {
+		return fmt.Errorf("golog: got %d level names, want %d", len(names), len(level_string))
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.levelNames = append([]string(nil), names...)
+	return nil
+}
+
+/* Zero or negative width pads to the longest level name. */
+func (l *Logger) SetLevelWidth(width int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.levelWidth = width
+}
+
+/* Must be called with lock held. */
+func (l *Logger) width() int {
+	if l.levelWidth > 0 {
+		return l.levelWidth
+	}
+	width := 0
+	for _, name := range l.levelNames {
+		if len(name) > width {
+			width = len(name)
+		}
+	}
+	return width
+}
+
+func SetLevelWidth(width int) {
+	std.SetLevelWidth(width)
+}
+
+func SetLevelNames(names []string) error {
+	return std.SetLevelNames(names)
+}
+
+var level_alias = map[string]Level{
+	"WARNING":  WARN,
+	"ERR":      ERROR,
+	"CRIT":     FATAL,
+	"CRITICAL": FATAL,
+}
+
+/* Accepts level names case insensitively, common aliases, and numeric level values. */
+func ParseLevel(str string) (level Level, err error) {
+	name := strings.ToUpper(strings.TrimSpace(str))
+	for l, s := range level_string {
+		if name == s {
+			return Level(l), nil
+		}
+	}
+	if level, ok := level_alias[name]; ok {
+		return level, nil
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 0 && n < len(level_string) {
+		return Level(n), nil
+	}
+	return INVALID, fmt.Errorf("golog: unknown level %q", str)
+}
+
+func ToLevel(str string) (level Level) {
+	level, _ = ParseLevel(str)
+	return
 }
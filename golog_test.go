@@ -0,0 +1,655 @@
+package golog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+/* Collects output written by the daemon, safe to read from the test. */
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func (b *syncBuffer) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.buf.Reset()
+}
+
+func (b *syncBuffer) Lines() []string {
+	s := strings.TrimSuffix(b.String(), "\n")
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, "\n")
+}
+
+/* A logger writing to w, stopped when the test ends. */
+func startLogger(t testing.TB, w io.Writer) *Logger {
+	l := New(w)
+	t.Cleanup(l.Stop)
+	return l
+}
+
+func newBufferLogger(t testing.TB) (*Logger, *syncBuffer) {
+	buf := new(syncBuffer)
+	return startLogger(t, buf), buf
+}
+
+/* Without timestamp and caller, so lines can be compared whole. */
+func plainLogger(t testing.TB, w io.Writer) *Logger {
+	l := startLogger(t, w)
+	l.SetTimeFormat("")
+	l.SetShowCaller(false)
+	return l
+}
+
+func newPlainLogger(t testing.TB) (*Logger, *syncBuffer) {
+	buf := new(syncBuffer)
+	return plainLogger(t, buf), buf
+}
+
+/* Flushes l and compares the lines written to buf with want. */
+func checkLines(t testing.TB, l *Logger, buf *syncBuffer, want ...string) {
+	t.Helper()
+	l.Flush()
+	if got := buf.Lines(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestFatalHonorsLevel(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	code := -1
+	l.SetExitFunc(func(c int) { code = c })
+	l.SetLogLevel(FATAL + 1)
+	l.Fatal("hidden")
+	l.Flush()
+	if out := buf.String(); out != "" {
+		t.Errorf("FATAL above the level was written: %q", out)
+	}
+	if code != 1 {
+		t.Errorf("exit code = %d, want 1", code)
+	}
+
+	code = -1
+	l.SetLogLevel(INFO)
+	l.SetFatalExit(false)
+	l.Fatal("shown")
+	if !strings.Contains(buf.String(), "shown") {
+		t.Errorf("FATAL message missing: %q", buf.String())
+	}
+	if code != -1 {
+		t.Errorf("exit called with %d after SetFatalExit(false)", code)
+	}
+}
+
+/* The line of the call to here. */
+func here() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func swallow() {
+	recover()
+}
+
+/* Reports its caller through InfofDepth, through std if l is nil. */
+func infofVia(l *Logger) {
+	if l == nil {
+		InfofDepth(1, "x")
+	} else {
+		l.InfofDepth(1, "x")
+	}
+}
+
+/* Every public logging function, called from the line it returns. */
+func TestCallSite(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	for _, lg := range []*Logger{l, std} {
+		lg.SetLogLevel(TRACE)
+		lg.SetFatalExit(false)
+		lg.SetTimeFormat("")
+	}
+	prevOut, prevFormat := SetOutput(buf), std.TimeFormat()
+	t.Cleanup(func() {
+		SetOutput(prevOut)
+		SetLogLevel(INFO)
+		SetFatalExit(true)
+		SetTimeFormat(prevFormat)
+	})
+	ctx := context.Background()
+	tests := []struct {
+		name string
+		call func(l *Logger) int
+	}{
+		{"Logger.Fatal", func(l *Logger) int { l.Fatal("x"); return here() }},
+		{"Logger.Fatalf", func(l *Logger) int { l.Fatalf("x %d", 1); return here() }},
+		{"Logger.FatalfDepth", func(l *Logger) int { l.FatalfDepth(0, "x"); return here() }},
+		{"Logger.FatalFunc", func(l *Logger) int { l.FatalFunc(func() string { return "x" }); return here() }},
+		{"Logger.Fatalw", func(l *Logger) int { l.Fatalw("x", "k", 1); return here() }},
+		{"Logger.FatalCtx", func(l *Logger) int { l.FatalCtx(ctx, "x"); return here() }},
+		{"Logger.FatalfCtx", func(l *Logger) int { l.FatalfCtx(ctx, "x %d", 1); return here() }},
+		{"Logger.WithPrefix.Fatal", func(l *Logger) int { l.WithPrefix("p: ").Fatal("x"); return here() }},
+		{"Logger.WithPrefix.Fatalf", func(l *Logger) int { l.WithPrefix("p: ").Fatalf("x %d", 1); return here() }},
+		{"Logger.WithPrefix.Fatalw", func(l *Logger) int { l.WithPrefix("p: ").Fatalw("x", "k", 1); return here() }},
+		{"Fatal", func(l *Logger) int { Fatal("x"); return here() }},
+		{"Fatalf", func(l *Logger) int { Fatalf("x %d", 1); return here() }},
+		{"FatalfDepth", func(l *Logger) int { FatalfDepth(0, "x"); return here() }},
+		{"FatalFunc", func(l *Logger) int { FatalFunc(func() string { return "x" }); return here() }},
+		{"Fatalw", func(l *Logger) int { Fatalw("x", "k", 1); return here() }},
+		{"FatalCtx", func(l *Logger) int { FatalCtx(ctx, "x"); return here() }},
+		{"FatalfCtx", func(l *Logger) int { FatalfCtx(ctx, "x %d", 1); return here() }},
+		{"WithPrefix.Fatal", func(l *Logger) int { WithPrefix("p: ").Fatal("x"); return here() }},
+		{"WithPrefix.Fatalf", func(l *Logger) int { WithPrefix("p: ").Fatalf("x %d", 1); return here() }},
+		{"WithPrefix.Fatalw", func(l *Logger) int { WithPrefix("p: ").Fatalw("x", "k", 1); return here() }},
+		{"Logger.Error", func(l *Logger) int { l.Error("x"); return here() }},
+		{"Logger.Errorf", func(l *Logger) int { l.Errorf("x %d", 1); return here() }},
+		{"Logger.ErrorfDepth", func(l *Logger) int { l.ErrorfDepth(0, "x"); return here() }},
+		{"Logger.ErrorFunc", func(l *Logger) int { l.ErrorFunc(func() string { return "x" }); return here() }},
+		{"Logger.Errorw", func(l *Logger) int { l.Errorw("x", "k", 1); return here() }},
+		{"Logger.ErrorCtx", func(l *Logger) int { l.ErrorCtx(ctx, "x"); return here() }},
+		{"Logger.ErrorfCtx", func(l *Logger) int { l.ErrorfCtx(ctx, "x %d", 1); return here() }},
+		{"Logger.WithPrefix.Error", func(l *Logger) int { l.WithPrefix("p: ").Error("x"); return here() }},
+		{"Logger.WithPrefix.Errorf", func(l *Logger) int { l.WithPrefix("p: ").Errorf("x %d", 1); return here() }},
+		{"Logger.WithPrefix.Errorw", func(l *Logger) int { l.WithPrefix("p: ").Errorw("x", "k", 1); return here() }},
+		{"Error", func(l *Logger) int { Error("x"); return here() }},
+		{"Errorf", func(l *Logger) int { Errorf("x %d", 1); return here() }},
+		{"ErrorfDepth", func(l *Logger) int { ErrorfDepth(0, "x"); return here() }},
+		{"ErrorFunc", func(l *Logger) int { ErrorFunc(func() string { return "x" }); return here() }},
+		{"Errorw", func(l *Logger) int { Errorw("x", "k", 1); return here() }},
+		{"ErrorCtx", func(l *Logger) int { ErrorCtx(ctx, "x"); return here() }},
+		{"ErrorfCtx", func(l *Logger) int { ErrorfCtx(ctx, "x %d", 1); return here() }},
+		{"WithPrefix.Error", func(l *Logger) int { WithPrefix("p: ").Error("x"); return here() }},
+		{"WithPrefix.Errorf", func(l *Logger) int { WithPrefix("p: ").Errorf("x %d", 1); return here() }},
+		{"WithPrefix.Errorw", func(l *Logger) int { WithPrefix("p: ").Errorw("x", "k", 1); return here() }},
+		{"Logger.Warn", func(l *Logger) int { l.Warn("x"); return here() }},
+		{"Logger.Warnf", func(l *Logger) int { l.Warnf("x %d", 1); return here() }},
+		{"Logger.WarnfDepth", func(l *Logger) int { l.WarnfDepth(0, "x"); return here() }},
+		{"Logger.WarnFunc", func(l *Logger) int { l.WarnFunc(func() string { return "x" }); return here() }},
+		{"Logger.Warnw", func(l *Logger) int { l.Warnw("x", "k", 1); return here() }},
+		{"Logger.WarnCtx", func(l *Logger) int { l.WarnCtx(ctx, "x"); return here() }},
+		{"Logger.WarnfCtx", func(l *Logger) int { l.WarnfCtx(ctx, "x %d", 1); return here() }},
+		{"Logger.WithPrefix.Warn", func(l *Logger) int { l.WithPrefix("p: ").Warn("x"); return here() }},
+		{"Logger.WithPrefix.Warnf", func(l *Logger) int { l.WithPrefix("p: ").Warnf("x %d", 1); return here() }},
+		{"Logger.WithPrefix.Warnw", func(l *Logger) int { l.WithPrefix("p: ").Warnw("x", "k", 1); return here() }},
+		{"Warn", func(l *Logger) int { Warn("x"); return here() }},
+		{"Warnf", func(l *Logger) int { Warnf("x %d", 1); return here() }},
+		{"WarnfDepth", func(l *Logger) int { WarnfDepth(0, "x"); return here() }},
+		{"WarnFunc", func(l *Logger) int { WarnFunc(func() string { return "x" }); return here() }},
+		{"Warnw", func(l *Logger) int { Warnw("x", "k", 1); return here() }},
+		{"WarnCtx", func(l *Logger) int { WarnCtx(ctx, "x"); return here() }},
+		{"WarnfCtx", func(l *Logger) int { WarnfCtx(ctx, "x %d", 1); return here() }},
+		{"WithPrefix.Warn", func(l *Logger) int { WithPrefix("p: ").Warn("x"); return here() }},
+		{"WithPrefix.Warnf", func(l *Logger) int { WithPrefix("p: ").Warnf("x %d", 1); return here() }},
+		{"WithPrefix.Warnw", func(l *Logger) int { WithPrefix("p: ").Warnw("x", "k", 1); return here() }},
+		{"Logger.Info", func(l *Logger) int { l.Info("x"); return here() }},
+		{"Logger.Infof", func(l *Logger) int { l.Infof("x %d", 1); return here() }},
+		{"Logger.InfofDepth", func(l *Logger) int { l.InfofDepth(0, "x"); return here() }},
+		{"Logger.InfoFunc", func(l *Logger) int { l.InfoFunc(func() string { return "x" }); return here() }},
+		{"Logger.Infow", func(l *Logger) int { l.Infow("x", "k", 1); return here() }},
+		{"Logger.InfoCtx", func(l *Logger) int { l.InfoCtx(ctx, "x"); return here() }},
+		{"Logger.InfofCtx", func(l *Logger) int { l.InfofCtx(ctx, "x %d", 1); return here() }},
+		{"Logger.WithPrefix.Info", func(l *Logger) int { l.WithPrefix("p: ").Info("x"); return here() }},
+		{"Logger.WithPrefix.Infof", func(l *Logger) int { l.WithPrefix("p: ").Infof("x %d", 1); return here() }},
+		{"Logger.WithPrefix.Infow", func(l *Logger) int { l.WithPrefix("p: ").Infow("x", "k", 1); return here() }},
+		{"Info", func(l *Logger) int { Info("x"); return here() }},
+		{"Infof", func(l *Logger) int { Infof("x %d", 1); return here() }},
+		{"InfofDepth", func(l *Logger) int { InfofDepth(0, "x"); return here() }},
+		{"InfoFunc", func(l *Logger) int { InfoFunc(func() string { return "x" }); return here() }},
+		{"Infow", func(l *Logger) int { Infow("x", "k", 1); return here() }},
+		{"InfoCtx", func(l *Logger) int { InfoCtx(ctx, "x"); return here() }},
+		{"InfofCtx", func(l *Logger) int { InfofCtx(ctx, "x %d", 1); return here() }},
+		{"WithPrefix.Info", func(l *Logger) int { WithPrefix("p: ").Info("x"); return here() }},
+		{"WithPrefix.Infof", func(l *Logger) int { WithPrefix("p: ").Infof("x %d", 1); return here() }},
+		{"WithPrefix.Infow", func(l *Logger) int { WithPrefix("p: ").Infow("x", "k", 1); return here() }},
+		{"Logger.Debug", func(l *Logger) int { l.Debug("x"); return here() }},
+		{"Logger.Debugf", func(l *Logger) int { l.Debugf("x %d", 1); return here() }},
+		{"Logger.DebugfDepth", func(l *Logger) int { l.DebugfDepth(0, "x"); return here() }},
+		{"Logger.DebugFunc", func(l *Logger) int { l.DebugFunc(func() string { return "x" }); return here() }},
+		{"Logger.Debugw", func(l *Logger) int { l.Debugw("x", "k", 1); return here() }},
+		{"Logger.DebugCtx", func(l *Logger) int { l.DebugCtx(ctx, "x"); return here() }},
+		{"Logger.DebugfCtx", func(l *Logger) int { l.DebugfCtx(ctx, "x %d", 1); return here() }},
+		{"Logger.WithPrefix.Debug", func(l *Logger) int { l.WithPrefix("p: ").Debug("x"); return here() }},
+		{"Logger.WithPrefix.Debugf", func(l *Logger) int { l.WithPrefix("p: ").Debugf("x %d", 1); return here() }},
+		{"Logger.WithPrefix.Debugw", func(l *Logger) int { l.WithPrefix("p: ").Debugw("x", "k", 1); return here() }},
+		{"Debug", func(l *Logger) int { Debug("x"); return here() }},
+		{"Debugf", func(l *Logger) int { Debugf("x %d", 1); return here() }},
+		{"DebugfDepth", func(l *Logger) int { DebugfDepth(0, "x"); return here() }},
+		{"DebugFunc", func(l *Logger) int { DebugFunc(func() string { return "x" }); return here() }},
+		{"Debugw", func(l *Logger) int { Debugw("x", "k", 1); return here() }},
+		{"DebugCtx", func(l *Logger) int { DebugCtx(ctx, "x"); return here() }},
+		{"DebugfCtx", func(l *Logger) int { DebugfCtx(ctx, "x %d", 1); return here() }},
+		{"WithPrefix.Debug", func(l *Logger) int { WithPrefix("p: ").Debug("x"); return here() }},
+		{"WithPrefix.Debugf", func(l *Logger) int { WithPrefix("p: ").Debugf("x %d", 1); return here() }},
+		{"WithPrefix.Debugw", func(l *Logger) int { WithPrefix("p: ").Debugw("x", "k", 1); return here() }},
+		{"Logger.Trace", func(l *Logger) int { l.Trace("x"); return here() }},
+		{"Logger.Tracef", func(l *Logger) int { l.Tracef("x %d", 1); return here() }},
+		{"Logger.TracefDepth", func(l *Logger) int { l.TracefDepth(0, "x"); return here() }},
+		{"Logger.TraceFunc", func(l *Logger) int { l.TraceFunc(func() string { return "x" }); return here() }},
+		{"Logger.Tracew", func(l *Logger) int { l.Tracew("x", "k", 1); return here() }},
+		{"Logger.TraceCtx", func(l *Logger) int { l.TraceCtx(ctx, "x"); return here() }},
+		{"Logger.TracefCtx", func(l *Logger) int { l.TracefCtx(ctx, "x %d", 1); return here() }},
+		{"Logger.WithPrefix.Trace", func(l *Logger) int { l.WithPrefix("p: ").Trace("x"); return here() }},
+		{"Logger.WithPrefix.Tracef", func(l *Logger) int { l.WithPrefix("p: ").Tracef("x %d", 1); return here() }},
+		{"Logger.WithPrefix.Tracew", func(l *Logger) int { l.WithPrefix("p: ").Tracew("x", "k", 1); return here() }},
+		{"Trace", func(l *Logger) int { Trace("x"); return here() }},
+		{"Tracef", func(l *Logger) int { Tracef("x %d", 1); return here() }},
+		{"TracefDepth", func(l *Logger) int { TracefDepth(0, "x"); return here() }},
+		{"TraceFunc", func(l *Logger) int { TraceFunc(func() string { return "x" }); return here() }},
+		{"Tracew", func(l *Logger) int { Tracew("x", "k", 1); return here() }},
+		{"TraceCtx", func(l *Logger) int { TraceCtx(ctx, "x"); return here() }},
+		{"TracefCtx", func(l *Logger) int { TracefCtx(ctx, "x %d", 1); return here() }},
+		{"WithPrefix.Trace", func(l *Logger) int { WithPrefix("p: ").Trace("x"); return here() }},
+		{"WithPrefix.Tracef", func(l *Logger) int { WithPrefix("p: ").Tracef("x %d", 1); return here() }},
+		{"WithPrefix.Tracew", func(l *Logger) int { WithPrefix("p: ").Tracew("x", "k", 1); return here() }},
+		{"Logger.Log", func(l *Logger) int { l.Log(INFO, "x"); return here() }},
+		{"Logger.Logf", func(l *Logger) int { l.Logf(INFO, "x %d", 1); return here() }},
+		{"Logger.Print", func(l *Logger) int { l.Print("x"); return here() }},
+		{"Logger.Printf", func(l *Logger) int { l.Printf("x %d", 1); return here() }},
+		{"Logger.Println", func(l *Logger) int { l.Println("x"); return here() }},
+		{"Logger.Fatalln", func(l *Logger) int { l.Fatalln("x"); return here() }},
+		{"Logger.ErrorStack", func(l *Logger) int { l.ErrorStack("x"); return here() }},
+		{"Logger.FatalStack", func(l *Logger) int { l.FatalStack("x"); return here() }},
+		{"Logger.ErrorErr", func(l *Logger) int { l.ErrorErr(errors.New("x")); return here() }},
+		{"Logger.Named", func(l *Logger) int { l.Named("n").Info("x"); return here() }},
+		{"Logger.WithFields", func(l *Logger) int { l.WithFields(map[string]interface{}{"k": 1}).Info("x"); return here() }},
+		{"Logger.WithError", func(l *Logger) int { l.WithError(errors.New("e")).Info("x"); return here() }},
+		{"Logger.LevelWriter", func(l *Logger) int { log.New(l.LevelWriter(INFO), "", 0).Print("x"); return here() }},
+		{"Logger.Panic", func(l *Logger) (line int) { defer swallow(); line = here(); l.Panic("x"); return }},
+		{"Logger.Panicf", func(l *Logger) (line int) { defer swallow(); line = here(); l.Panicf("x %d", 1); return }},
+		{"Logger.Panicln", func(l *Logger) (line int) { defer swallow(); line = here(); l.Panicln("x"); return }},
+		{"Log", func(l *Logger) int { Log(INFO, "x"); return here() }},
+		{"Logf", func(l *Logger) int { Logf(INFO, "x %d", 1); return here() }},
+		{"Print", func(l *Logger) int { Print("x"); return here() }},
+		{"Printf", func(l *Logger) int { Printf("x %d", 1); return here() }},
+		{"Println", func(l *Logger) int { Println("x"); return here() }},
+		{"Fatalln", func(l *Logger) int { Fatalln("x"); return here() }},
+		{"ErrorStack", func(l *Logger) int { ErrorStack("x"); return here() }},
+		{"FatalStack", func(l *Logger) int { FatalStack("x"); return here() }},
+		{"ErrorErr", func(l *Logger) int { ErrorErr(errors.New("x")); return here() }},
+		{"Named", func(l *Logger) int { Named("n").Info("x"); return here() }},
+		{"WithFields", func(l *Logger) int { WithFields(map[string]interface{}{"k": 1}).Info("x"); return here() }},
+		{"WithError", func(l *Logger) int { WithError(errors.New("e")).Info("x"); return here() }},
+		{"LevelWriter", func(l *Logger) int { log.New(LevelWriter(INFO), "", 0).Print("x"); return here() }},
+		{"Panic", func(l *Logger) (line int) { defer swallow(); line = here(); Panic("x"); return }},
+		{"Panicf", func(l *Logger) (line int) { defer swallow(); line = here(); Panicf("x %d", 1); return }},
+		{"Panicln", func(l *Logger) (line int) { defer swallow(); line = here(); Panicln("x"); return }},
+		{"Logger.InfofDepth skip 1", func(l *Logger) int { infofVia(l); return here() }},
+		{"InfofDepth skip 1", func(l *Logger) int { infofVia(nil); return here() }},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		line := tt.call(l)
+		l.Flush()
+		Flush()
+		lines := buf.Lines()
+		want := fmt.Sprintf("[golog_test.go:%d] ", line)
+		if len(lines) == 0 || !strings.Contains(lines[0], want) {
+			t.Errorf("%s: got %q, want caller %s", tt.name, lines, want)
+		}
+	}
+}
+
+/* Everything queued ahead of a FATAL message is written by the time exit is called. */
+func TestFatalDrainsQueue(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	var lines []string
+	l.SetExitFunc(func(int) { lines = buf.Lines() })
+	const n = 1000
+	for i := 0; i < n; i++ {
+		l.Infof("line %d", i)
+	}
+	l.Fatal("fatal")
+	if len(lines) != n+1 {
+		t.Fatalf("got %d lines before exit, want %d", len(lines), n+1)
+	}
+	for i, line := range lines[:n] {
+		if want := fmt.Sprintf("] line %d", i); !strings.HasSuffix(line, want) {
+			t.Fatalf("line %d = %q, want suffix %q", i, line, want)
+		}
+	}
+	if !strings.HasSuffix(lines[n], "] fatal") || !strings.Contains(lines[n], "FATAL") {
+		t.Errorf("last line = %q, want the FATAL message", lines[n])
+	}
+}
+
+/* Run with -race: outputs are swapped under the daemon while it writes. */
+func TestSwapOutputWhileLogging(t *testing.T) {
+	l, first := newBufferLogger(t)
+	l.SetVerboseInternal(false)
+	path := filepath.Join(t.TempDir(), "swap.log")
+	const writers, n = 4, 500
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				l.Infof("line %d", i)
+			}
+		}()
+	}
+	bufs := []*syncBuffer{first}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			b, extra := new(syncBuffer), new(syncBuffer)
+			bufs = append(bufs, b)
+			switch i % 3 {
+			case 0:
+				l.OpenWriter(b)
+			case 1:
+				/* A file opened by Open is ours to close once swapped out. */
+				if f, ok := l.SetOutput(b).(*os.File); ok {
+					f.Close()
+				}
+			case 2:
+				if err := l.Open(path); err != nil {
+					t.Error(err)
+				}
+			}
+			l.AddOutput(extra)
+			l.RemoveOutput(extra)
+		}
+	}()
+	wg.Wait()
+	<-done
+	l.Flush()
+	total := 0
+	for _, b := range bufs {
+		total += len(b.Lines())
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total += strings.Count(string(data), "\n")
+	if total != writers*n {
+		t.Errorf("got %d lines across outputs, want %d", total, writers*n)
+	}
+}
+
+func BenchmarkInfo(b *testing.B) {
+	l := startLogger(b, io.Discard)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("benchmark")
+	}
+	l.Flush()
+}
+
+/* Nothing is formatted, what is left are the allocations of the logging call itself. */
+func BenchmarkInfoDiscard(b *testing.B) {
+	l := startLogger(b, io.Discard)
+	l.Discard()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("benchmark")
+	}
+	l.Flush()
+}
+
+func TestLogfFormatting(t *testing.T) {
+	l, buf := newPlainLogger(t)
+	args := []int{1, 2}
+	l.Infof("100%%")
+	l.Infof("plain")
+	l.Infof("rate %d%%", 5)
+	l.Infof("%v", args)
+	args[0] = 9
+	checkLines(t, l, buf, "[ INFO] 100%", "[ INFO] plain", "[ INFO] rate 5%", "[ INFO] [1 2]")
+}
+
+/* See enqueue: per goroutine call order is kept and no line is torn. */
+func TestOrdering(t *testing.T) {
+	l, buf := newPlainLogger(t)
+	const goroutines, n = 16, 1000
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				l.Infof("g=%d i=%d %s", g, i, strings.Repeat("x", i%64))
+			}
+		}(g)
+	}
+	wg.Wait()
+	l.Flush()
+	re := regexp.MustCompile(`^\[ INFO\] g=(\d+) i=(\d+) (x*)$`)
+	next := make([]int, goroutines)
+	for _, line := range buf.Lines() {
+		m := re.FindStringSubmatch(line)
+		if m == nil {
+			t.Fatalf("torn line %q", line)
+		}
+		var g, i int
+		fmt.Sscan(m[1], &g)
+		fmt.Sscan(m[2], &i)
+		if i != next[g] || len(m[3]) != i%64 {
+			t.Fatalf("goroutine %d: got line %q, want i=%d", g, line, next[g])
+		}
+		next[g]++
+	}
+	for g, i := range next {
+		if i != n {
+			t.Errorf("goroutine %d: got %d lines, want %d", g, i, n)
+		}
+	}
+}
+
+/* Run with -race: Start, Stop and Flush race with each other and with logging. */
+func TestStartStopConcurrent(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	const n = 200
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				l.Start()
+				l.Stop()
+				l.Flush()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				l.Info("line")
+			}
+		}()
+	}
+	wg.Wait()
+	l.Start()
+	l.Info("line")
+	l.Flush()
+	if got := len(buf.Lines()); got != 4*n+1 {
+		t.Errorf("got %d lines, want %d", got, 4*n+1)
+	}
+}
+
+func TestStopStart(t *testing.T) {
+	l, buf := newPlainLogger(t)
+	l.Info("before")
+	l.Stop()
+	l.Info("stopped")
+	l.Start()
+	l.Info("after")
+	checkLines(t, l, buf, "[ INFO] before", "[ INFO] stopped", "[ INFO] after")
+}
+
+/* Repeated calls from one site hit caller_cache. */
+func BenchmarkCaller(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		caller(0)
+	}
+}
+
+/* The uncached lookup caller falls back to on a miss. */
+func BenchmarkCallerUncached(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var pcs [1]uintptr
+		runtime.Callers(2, pcs[:])
+		runtime.CallersFrames(pcs[:]).Next()
+	}
+}
+
+func TestFileMode(t *testing.T) {
+	dir := t.TempDir()
+	mode := func(path string) os.FileMode {
+		st, err := os.Stat(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return st.Mode().Perm()
+	}
+	l, _ := newBufferLogger(t)
+	l.SetVerboseInternal(false)
+	l.SetFileMode(0600)
+	path := filepath.Join(dir, "app.log")
+	if err := l.Open(path); err != nil {
+		t.Fatal(err)
+	}
+	if m := mode(path); m != 0600 {
+		t.Errorf("Open created mode %v, want 0600", m)
+	}
+	/* External rotation moved the file away, Rotate creates it anew. */
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if m := mode(path); m != 0600 {
+		t.Errorf("Rotate created mode %v, want 0600", m)
+	}
+
+	SetFileMode(0600)
+	t.Cleanup(func() { SetFileMode(0660) })
+	fl, err := NewFile(filepath.Join(dir, "new.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fl.close()
+	if m := mode(filepath.Join(dir, "new.log")); m != 0600 {
+		t.Errorf("NewFile created mode %v, want 0600", m)
+	}
+}
+
+/* A relative path given to Open is resolved once, so Rotate ignores a later chdir. */
+func TestRotateAfterChdir(t *testing.T) {
+	orig, moved := t.TempDir(), t.TempDir()
+	t.Chdir(orig)
+	l, _ := newBufferLogger(t)
+	l.SetVerboseInternal(false)
+	if err := l.Open("rel.log"); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("before")
+	l.Flush()
+	t.Chdir(moved)
+	if err := os.Rename(filepath.Join(orig, "rel.log"), filepath.Join(orig, "rel.log.1")); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("after")
+	l.Flush()
+	if _, err := os.Stat(filepath.Join(moved, "rel.log")); !os.IsNotExist(err) {
+		t.Errorf("Rotate created rel.log in the new working directory")
+	}
+	data, err := os.ReadFile(filepath.Join(orig, "rel.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "after") || strings.Contains(string(data), "before") {
+		t.Errorf("reopened file holds %q, want only the line logged after Rotate", data)
+	}
+}
+
+/* Run with -race: the getters are used while the setters and the daemon run. */
+func TestPrefixTimeFormatRace(t *testing.T) {
+	l, _ := newBufferLogger(t)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			l.SetPrefix(fmt.Sprintf("p%d ", i))
+			l.SetTimeFormat("15:04:05")
+			l.Info("x")
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		_, _ = l.Prefix(), l.TimeFormat()
+	}
+	<-done
+	l.Flush()
+	if l.Prefix() != "p199 " {
+		t.Errorf("Prefix() = %q, want %q", l.Prefix(), "p199 ")
+	}
+}
+
+/* Messages enqueued while Stop runs are either drained or written inline, never lost. */
+func TestStopWhileLogging(t *testing.T) {
+	for iter := 0; iter < 50; iter++ {
+		l, buf := newBufferLogger(t)
+		l.SetFatalExit(false)
+		const goroutines, n = 4, 100
+		var wg sync.WaitGroup
+		for g := 0; g < goroutines; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < n-1; i++ {
+					l.Info("line")
+				}
+				l.Fatal("last")
+			}()
+		}
+		l.Stop()
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(10 * time.Second):
+			t.Fatal("a FATAL call enqueued during Stop never returned")
+		}
+		if got := len(buf.Lines()); got != goroutines*n {
+			t.Fatalf("got %d lines, want %d", got, goroutines*n)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package golog
+
+/*
+ * A Handler receives every message written by a logger, next to its outputs.
+ * Handle is called by the daemon with the logger lock held, so it must not
+ * log to the same logger. Errors are passed to the error handler.
+ */
+type Handler interface {
+	Handle(msg Message) error
+}
+
+func (l *Logger) AddHandler(h Handler) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.handlers = append(l.handlers, h)
+}
+
+/* h must be comparable, as the first handler equal to it is removed. */
+func (l *Logger) RemoveHandler(h Handler) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for i, o := range l.handlers {
+		if o == h {
+			l.handlers = append(l.handlers[:i], l.handlers[i+1:]...)
+			return
+		}
+	}
+}
+
+func AddHandler(h Handler) {
+	std.AddHandler(h)
+}
+
+func RemoveHandler(h Handler) {
+	std.RemoveHandler(h)
+}
+
+/* Must be called with lock held. */
+func (l *Logger) handle(msg *Message) {
+	for _, h := range l.handlers {
+		if err := h.Handle(*msg); err != nil && l.onError != nil {
+			l.onError(err)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package golog
+
+/*
+ * The filter is called by the daemon for every message about to be written,
+ * returning false drops it. It runs with the logger lock held and must be
+ * cheap. nil removes it.
+ */
+func (l *Logger) SetFilter(filter func(*Message) bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.filter = filter
+}
+
+/* Like the filter, the transform runs before formatting and may rewrite the message. */
+func (l *Logger) SetTransform(transform func(*Message)) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.transform = transform
+}
+
+func SetFilter(filter func(*Message) bool) {
+	std.SetFilter(filter)
+}
+
+func SetTransform(transform func(*Message)) {
+	std.SetTransform(transform)
+}
+
+func (m *Message) SetText(text string) {
+	m.message = text
+}
+
+func (m *Message) SetFields(fields []Field) {
+	m.fields = fields
+}
+
+/* Must be called with lock held. Reports whether msg should still be written. */
+func (l *Logger) hook(msg *Message) bool {
+	if l.filter != nil && !l.filter(msg) {
+		return false
+	}
+	if l.transform != nil {
+		l.transform(msg)
+	}
+	l.redact(msg)
+	return true
+}
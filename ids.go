@@ -0,0 +1,82 @@
+package golog
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+)
+
+var pid = os.Getpid()
+
+/*
+ * Parses the id out of the "goroutine N [running]:" header of the current
+ * stack. The runtime offers no cheaper way, so it is only done when enabled.
+ */
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+/* Adds a pid field to every message. */
+func (l *Logger) SetShowPID(show bool) {
+	if show {
+		atomic.StoreInt32(&l.showPID, 1)
+	} else {
+		atomic.StoreInt32(&l.showPID, 0)
+	}
+}
+
+/* Adds a goroutine field with the id of the logging goroutine to every message. */
+func (l *Logger) SetShowGoroutine(show bool) {
+	if show {
+		atomic.StoreInt32(&l.showGoroutine, 1)
+	} else {
+		atomic.StoreInt32(&l.showGoroutine, 0)
+	}
+}
+
+/*
+ * Adds a seq field numbering the messages of the logger from 1. Numbers are
+ * taken before the overflow policy applies, so dropped messages leave gaps.
+ */
+func (l *Logger) SetShowSeq(show bool) {
+	if show {
+		atomic.StoreInt32(&l.showSeq, 1)
+	} else {
+		atomic.StoreInt32(&l.showSeq, 0)
+	}
+}
+
+func SetShowSeq(show bool) {
+	std.SetShowSeq(show)
+}
+
+func SetShowPID(show bool) {
+	std.SetShowPID(show)
+}
+
+func SetShowGoroutine(show bool) {
+	std.SetShowGoroutine(show)
+}
+
+/* Called by the logging goroutine, the goroutine id is meaningless anywhere else. */
+func (l *Logger) idFields(fields []Field) []Field {
+	if atomic.LoadInt32(&l.showSeq) != 0 {
+		fields = append(fields, Field{"seq", atomic.AddUint64(&l.seq, 1)})
+	}
+	if atomic.LoadInt32(&l.showPID) != 0 {
+		fields = append(fields, Field{"pid", pid})
+	}
+	if atomic.LoadInt32(&l.showGoroutine) != 0 {
+		fields = append(fields, Field{"goroutine", goroutineID()})
+	}
+	return fields
+}
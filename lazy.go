@@ -0,0 +1,60 @@
+package golog
+
+/* fn is only called when the level is enabled, the *Func variants keep expensive messages off disabled paths. */
+func (l *Logger) logFunc(level Level, fn func() string) {
+	if l.loadLevel() > level {
+		if level == FATAL {
+			l.fatalExit()
+		}
+		return
+	}
+	l.log(level, 1, fn(), nil)
+}
+
+func (l *Logger) FatalFunc(fn func() string) {
+	l.logFunc(FATAL, fn)
+}
+
+func (l *Logger) ErrorFunc(fn func() string) {
+	l.logFunc(ERROR, fn)
+}
+
+func (l *Logger) WarnFunc(fn func() string) {
+	l.logFunc(WARN, fn)
+}
+
+func (l *Logger) InfoFunc(fn func() string) {
+	l.logFunc(INFO, fn)
+}
+
+func (l *Logger) DebugFunc(fn func() string) {
+	l.logFunc(DEBUG, fn)
+}
+
+func (l *Logger) TraceFunc(fn func() string) {
+	l.logFunc(TRACE, fn)
+}
+
+func FatalFunc(fn func() string) {
+	std.logFunc(FATAL, fn)
+}
+
+func ErrorFunc(fn func() string) {
+	std.logFunc(ERROR, fn)
+}
+
+func WarnFunc(fn func() string) {
+	std.logFunc(WARN, fn)
+}
+
+func InfoFunc(fn func() string) {
+	std.logFunc(INFO, fn)
+}
+
+func DebugFunc(fn func() string) {
+	std.logFunc(DEBUG, fn)
+}
+
+func TraceFunc(fn func() string) {
+	std.logFunc(TRACE, fn)
+}
@@ -0,0 +1,29 @@
+package golog
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+/* Stands in for a message that is costly to build. */
+func expensive() string {
+	return strings.Repeat("state ", 32)
+}
+
+/* DEBUG is disabled in both: Debugf still pays for its arguments, DebugFunc does not. */
+func BenchmarkDebugfDisabled(b *testing.B) {
+	l := startLogger(b, io.Discard)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Debugf("state: %s", expensive())
+	}
+}
+
+func BenchmarkDebugFuncDisabled(b *testing.B) {
+	l := startLogger(b, io.Discard)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.DebugFunc(func() string { return "state: " + expensive() })
+	}
+}
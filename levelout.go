@@ -0,0 +1,107 @@
+package golog
+
+import (
+	"io"
+)
+
+/*
+ * Messages at level go to the given writers instead of the main output,
+ * outputs added with AddOutput still receive them, but a writer never gets
+ * the same message twice. No writers restores the main output for that level.
+ * Nil writers are ignored with a warning, if all are nil the level is left as is.
+ */
+func (l *Logger) SetLevelOutput(level Level, w ...io.Writer) {
+	if len(w) > 0 {
+		valid := make([]io.Writer, 0, len(w))
+		for _, o := range w {
+			if nilWriter(o) {
+				l.Warnf("SetLevelOutput called with a nil writer for %s, ignoring it", level)
+			} else {
+				valid = append(valid, o)
+			}
+		}
+		if len(valid) == 0 {
+			return
+		}
+		w = valid
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, o := range l.byLevel[level] {
+		o.flush()
+	}
+	if len(w) == 0 {
+		delete(l.byLevel, level)
+		return
+	}
+	if l.byLevel == nil {
+		l.byLevel = make(map[Level][]*FileLog)
+	}
+	dests := make([]*FileLog, len(w))
+	for i := range w {
+		dests[i] = l.levelOutput(w[i])
+	}
+	l.byLevel[level] = dests
+}
+
+/* Must be called with lock held. Shares one buffer per writer, so buffered lines never tear. */
+func (l *Logger) levelOutput(w io.Writer) *FileLog {
+	if o := l.findOutput(w); o != nil {
+		return o
+	}
+	return NewWriter(w)
+}
+
+/* Must be called with lock held. Returns the output writing to w, nil if there is none. */
+func (l *Logger) findOutput(w io.Writer) *FileLog {
+	if l.out.writer == w {
+		return l.out
+	}
+	for _, o := range l.outputs {
+		if o.writer == w {
+			return o
+		}
+	}
+	for _, dests := range l.byLevel {
+		for _, o := range dests {
+			if o.writer == w {
+				return o
+			}
+		}
+	}
+	if l.console != nil && l.console.writer == w {
+		return l.console
+	}
+	return nil
+}
+
+/* Must be called with lock held. */
+func (l *Logger) levelOutputs(level Level) []*FileLog {
+	return l.byLevel[level]
+}
+
+/*
+ * Must be called with lock held. Replaces the main output by fl, a reopen of
+ * the same file, also where it is shared as a level, added or console output.
+ */
+func (l *Logger) reopened(fl *FileLog) {
+	old := l.out
+	fl.format = old.format
+	old.close()
+	l.out = fl
+	for i := range l.outputs {
+		if l.outputs[i] == old {
+			l.outputs[i] = fl
+		}
+	}
+	for _, dests := range l.byLevel {
+		for i := range dests {
+			if dests[i] == old {
+				dests[i] = fl
+			}
+		}
+	}
+	if l.console == old {
+		l.console = fl
+	}
+}
@@ -0,0 +1,72 @@
+package golog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLevelOutputOnce(t *testing.T) {
+	l, main := newPlainLogger(t)
+	errs := new(syncBuffer)
+	l.SetLevelOutput(ERROR, errs)
+	l.AddOutput(errs)
+	l.Info("info")
+	l.Error("error")
+	checkLines(t, l, main, "[ INFO] info")
+	checkLines(t, l, errs, "[ INFO] info", "[ERROR] error")
+}
+
+/* Lines routed by level to the main output count towards its size and rollover. */
+func TestLevelOutputMainRollover(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	l, _ := newPlainLogger(t)
+	l.SetVerboseInternal(false)
+	if err := l.Open(path); err != nil {
+		t.Fatal(err)
+	}
+	l.lock.Lock()
+	file := l.out.writer
+	l.lock.Unlock()
+	l.SetLevelOutput(ERROR, file)
+	l.SetMaxSize(1)
+	l.Error("first")
+	l.Error("second")
+	l.Flush()
+	backups, _ := filepath.Glob(filepath.Join(dir, "app.log.*"))
+	if len(backups) != 1 {
+		t.Errorf("got backups %q, want one after the second line", backups)
+	}
+	/* The level output follows the main output to the reopened file. */
+	l.SetMaxSize(0)
+	l.Error("third")
+	l.Flush()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "[ERROR] second\n[ERROR] third\n" {
+		t.Errorf("main output holds %q, want the lines after the rollover", data)
+	}
+}
+
+func TestNilOutputsIgnored(t *testing.T) {
+	l, buf := newPlainLogger(t)
+	var f *os.File
+	errs := new(syncBuffer)
+	l.AddOutput(nil)
+	l.AddOutput(f)
+	l.SetLevelOutput(ERROR, errs, nil)
+	l.SetLevelOutput(ERROR, f)
+	if prev := l.SetOutput(nil); prev != buf {
+		t.Errorf("SetOutput(nil) returned %v, want the current output", prev)
+	}
+	l.Info("info")
+	l.Error("error")
+	checkLines(t, l, errs, "[ERROR] error")
+	lines := buf.Lines()
+	if len(lines) != 6 || lines[5] != "[ INFO] info" {
+		t.Errorf("got %q, want five warnings and the info line", lines)
+	}
+}
@@ -0,0 +1,53 @@
+package golog
+
+import (
+	"strings"
+)
+
+/* Named entries are leveled by SetLevelFor, falling back to their parents and finally the logger level. */
+func (l *Logger) Named(name string) *Entry {
+	return &Entry{logger: l, name: name}
+}
+
+/* The child name is appended to the entry name with a dot, e.g. db.pool. */
+func (e *Entry) Named(name string) *Entry {
+	if e.name != "" {
+		name = e.name + "." + name
+	}
+	return &Entry{logger: e.logger, fields: e.fields, prefix: e.prefix, name: name}
+}
+
+/* INVALID removes the override, so the name inherits again. */
+func (l *Logger) SetLevelFor(name string, level Level) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	old, _ := l.levels.Load().(map[string]Level)
+	levels := make(map[string]Level, len(old)+1)
+	for k, v := range old {
+		levels[k] = v
+	}
+	if level == INVALID {
+		delete(levels, name)
+	} else {
+		levels[name] = level
+	}
+	l.levels.Store(levels)
+}
+
+func (l *Logger) levelFor(e *Entry) Level {
+	if e == nil || e.name == "" {
+		return l.loadLevel()
+	}
+	levels, _ := l.levels.Load().(map[string]Level)
+	for name := e.name; len(levels) > 0; {
+		if level, ok := levels[name]; ok {
+			return level
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return l.loadLevel()
+}
@@ -0,0 +1,99 @@
+package golog
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+/* Level used by Print, Printf and Println, INFO by default. */
+func (l *Logger) SetDefaultLevel(level Level) {
+	atomic.StoreInt32(&l.printLevel, int32(level))
+}
+
+func (l *Logger) defaultLevel() Level {
+	return Level(atomic.LoadInt32(&l.printLevel))
+}
+
+/* FATAL must still reach log, which exits even when the message is filtered. */
+func (l *Logger) printEnabled() bool {
+	level := l.defaultLevel()
+	return level == FATAL || l.IsEnabled(level)
+}
+
+func (l *Logger) Print(v ...interface{}) {
+	if l.printEnabled() {
+		l.log(l.defaultLevel(), 0, fmt.Sprint(v...), nil)
+	}
+}
+
+func (l *Logger) Printf(format string, v ...interface{}) {
+	l.logf(l.defaultLevel(), 0, format, v, nil)
+}
+
+func (l *Logger) Println(v ...interface{}) {
+	if l.printEnabled() {
+		l.log(l.defaultLevel(), 0, strings.TrimSuffix(fmt.Sprintln(v...), "\n"), nil)
+	}
+}
+
+/* Fatalln and the Panic functions complete the methods of the standard log.Logger. */
+func (l *Logger) Fatalln(v ...interface{}) {
+	l.log(FATAL, 0, strings.TrimSuffix(fmt.Sprintln(v...), "\n"), nil)
+}
+
+/* Logs msg at FATAL, waits for it to be written, then panics with msg instead of exiting. */
+func (l *Logger) panic(skip int, msg string) {
+	if l.send(FATAL, skip+1, msg, nil, nil, nil) {
+		<-l.quit_signal
+	}
+	panic(msg)
+}
+
+func (l *Logger) Panic(v ...interface{}) {
+	l.panic(0, fmt.Sprint(v...))
+}
+
+func (l *Logger) Panicf(format string, v ...interface{}) {
+	l.panic(0, fmt.Sprintf(format, v...))
+}
+
+func (l *Logger) Panicln(v ...interface{}) {
+	l.panic(0, strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
+
+func SetDefaultLevel(level Level) {
+	std.SetDefaultLevel(level)
+}
+
+func Print(v ...interface{}) {
+	if std.printEnabled() {
+		std.log(std.defaultLevel(), 0, fmt.Sprint(v...), nil)
+	}
+}
+
+func Printf(format string, v ...interface{}) {
+	std.logf(std.defaultLevel(), 0, format, v, nil)
+}
+
+func Println(v ...interface{}) {
+	if std.printEnabled() {
+		std.log(std.defaultLevel(), 0, strings.TrimSuffix(fmt.Sprintln(v...), "\n"), nil)
+	}
+}
+
+func Fatalln(v ...interface{}) {
+	std.log(FATAL, 0, strings.TrimSuffix(fmt.Sprintln(v...), "\n"), nil)
+}
+
+func Panic(v ...interface{}) {
+	std.panic(0, fmt.Sprint(v...))
+}
+
+func Panicf(format string, v ...interface{}) {
+	std.panic(0, fmt.Sprintf(format, v...))
+}
+
+func Panicln(v ...interface{}) {
+	std.panic(0, strings.TrimSuffix(fmt.Sprintln(v...), "\n"))
+}
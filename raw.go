@@ -0,0 +1,38 @@
+package golog
+
+import "time"
+
+/*
+ * Queues b to be written verbatim, without level, time or caller, to the main
+ * and added outputs. It keeps its place among the messages logged around it.
+ */
+func (l *Logger) RawWrite(b []byte) {
+	/* Nothing to write, and a nil raw would be taken for a regular message. */
+	if len(b) == 0 {
+		return
+	}
+	m := message_pool.Get().(*Message)
+	m.raw = append([]byte(nil), b...)
+	m.level = INVALID
+	l.enqueue(m)
+}
+
+func RawWrite(b []byte) {
+	std.RawWrite(b)
+}
+
+/* Must be called with lock held. */
+func (l *Logger) writeRaw(b []byte) {
+	l.flushRepeats()
+	l.checkRollover(int64(len(b)), time.Now())
+	n, err := l.writeLine(l.out, b)
+	l.out.size += int64(n)
+	if err != nil && l.onError != nil {
+		l.onError(err)
+	}
+	for _, o := range l.outputs {
+		if _, err := l.writeLine(o, b); err != nil && l.onError != nil {
+			l.onError(err)
+		}
+	}
+}
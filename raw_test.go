@@ -0,0 +1,29 @@
+package golog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRawWrite(t *testing.T) {
+	l, buf := newPlainLogger(t)
+	l.Info("before")
+	l.RawWrite([]byte("-----\n"))
+	l.RawWrite(nil)
+	l.RawWrite([]byte{})
+	l.Info("after")
+	checkLines(t, l, buf, "[ INFO] before", "-----", "[ INFO] after")
+}
+
+/* Outputs rendering messages themselves bypass the buffer raw bytes go through. */
+func TestRawWriteMessageWriter(t *testing.T) {
+	ft := new(fakeT)
+	l := plainLogger(t, testWriter{ft})
+	l.RawWrite([]byte("=== banner ===\n"))
+	l.Info("after banner")
+	l.Flush()
+	want := []string{"=== banner ===", "[ INFO] after banner"}
+	if strings.Join(ft.logs, "\n") != strings.Join(want, "\n") {
+		t.Errorf("got %q, want %q", ft.logs, want)
+	}
+}
@@ -0,0 +1,44 @@
+package golog
+
+import (
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+/* Nil fields of Options are left unchanged by Reconfigure. */
+type Options struct {
+	Level  *Level
+	Prefix *string
+	Format Formatter
+	Output io.Writer
+}
+
+/*
+ * Applies all of opts under a single lock acquisition, so no message is
+ * rendered with only part of the new configuration. The level is checked by
+ * the logging call, a message racing with Reconfigure may still be filtered
+ * by the old level but is written with the new settings.
+ */
+func (l *Logger) Reconfigure(opts Options) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if opts.Level != nil {
+		atomic.StoreInt32(&l.level, int32(*opts.Level))
+	}
+	if opts.Prefix != nil {
+		l.prefix = *opts.Prefix
+	}
+	if opts.Format != nil {
+		l.format = opts.Format
+	}
+	if opts.Output != nil {
+		l.out.close()
+		l.out = NewWriter(opts.Output)
+		l.periodEnd = time.Time{}
+	}
+}
+
+func Reconfigure(opts Options) {
+	std.Reconfigure(opts)
+}
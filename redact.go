@@ -0,0 +1,36 @@
+package golog
+
+import (
+	"regexp"
+)
+
+type redactor struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+/*
+ * Matches of pattern in the message and in string field values are replaced
+ * before writing, as by ReplaceAllString. Redactors apply in the order added.
+ */
+func (l *Logger) AddRedactor(pattern *regexp.Regexp, replacement string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.redactors = append(l.redactors, redactor{pattern, replacement})
+}
+
+func AddRedactor(pattern *regexp.Regexp, replacement string) {
+	std.AddRedactor(pattern, replacement)
+}
+
+/* Must be called with lock held. */
+func (l *Logger) redact(msg *Message) {
+	for _, r := range l.redactors {
+		msg.message = r.pattern.ReplaceAllString(msg.message, r.replacement)
+		for i := range msg.fields {
+			if s, ok := msg.fields[i].Value.(string); ok {
+				msg.fields[i].Value = r.pattern.ReplaceAllString(s, r.replacement)
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package golog
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestRedactorOrder(t *testing.T) {
+	l, buf := newBufferLogger(t)
+	l.AddRedactor(regexp.MustCompile(`token=\w+`), "token=<secret>")
+	l.AddRedactor(regexp.MustCompile(`<secret>`), "***")
+	l.Info("login token=abc123", Str("auth", "token=def"))
+	l.Flush()
+	out := buf.String()
+	if strings.Contains(out, "abc123") || strings.Contains(out, "def") {
+		t.Fatalf("secret left in %q", out)
+	}
+	if !strings.HasSuffix(out, `login token=*** auth="token=***"`+"\n") {
+		t.Errorf("redactors not applied in order: %q", out)
+	}
+}
+
+/* Every redactor runs its pattern over every message and string field. */
+func BenchmarkRedact(b *testing.B) {
+	for _, n := range []int{0, 1, 4} {
+		b.Run(fmt.Sprintf("redactors=%d", n), func(b *testing.B) {
+			l := startLogger(b, io.Discard)
+			for i := 0; i < n; i++ {
+				l.AddRedactor(regexp.MustCompile(`(?i)bearer [a-z0-9._-]+`), "bearer ***")
+			}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				l.Info("request from 10.0.0.1 with header Bearer abc.def-123", Str("path", "/api/v1/items"))
+			}
+			l.Flush()
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package golog
+
+import (
+	"sync"
+)
+
+/* Keeps the most recent formatted lines, overwriting the oldest when full. */
+type ring struct {
+	lock  sync.Mutex
+	lines []string
+	next  int
+	full  bool
+}
+
+func (r *ring) add(line string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.lines[r.next] = line
+	r.next++
+	if r.next == len(r.lines) {
+		r.next = 0
+		r.full = true
+	}
+}
+
+func (r *ring) contents() (lines []string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.full {
+		return append(lines, r.lines[:r.next]...)
+	}
+	lines = append(lines, r.lines[r.next:]...)
+	return append(lines, r.lines[:r.next]...)
+}
+
+/* Keeps the last n formatted lines in memory, n <= 0 disables the buffer. */
+func (l *Logger) EnableRingBuffer(n int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if n <= 0 {
+		l.ring = nil
+		return
+	}
+	l.ring = &ring{lines: make([]string, n)}
+}
+
+/* Returns the buffered lines, oldest first. */
+func (l *Logger) RingBufferContents() []string {
+	l.lock.Lock()
+	r := l.ring
+	l.lock.Unlock()
+	if r == nil {
+		return nil
+	}
+	return r.contents()
+}
+
+func EnableRingBuffer(n int) {
+	std.EnableRingBuffer(n)
+}
+
+func RingBufferContents() []string {
+	return std.RingBufferContents()
+}
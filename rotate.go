@@ -0,0 +1,254 @@
+package golog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const backupTimeFormat = "20060102-150405.000"
+
+/* Zero or negative size disables size based rotation. Only applies to logs opened by path. */
+func (l *Logger) SetMaxSize(bytes int64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.maxSize = bytes
+}
+
+/* Multiples of 24 hours rotate at local midnight, other intervals at multiples of d. */
+func (l *Logger) SetRotateInterval(d time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.interval = d
+	l.periodEnd = time.Time{}
+}
+
+const oneDay = 24 * time.Hour
+
+func period(t time.Time, d time.Duration) (start, end time.Time) {
+	if d%oneDay == 0 {
+		y, m, dd := t.Date()
+		start = time.Date(y, m, dd, 0, 0, 0, 0, t.Location())
+		return start, time.Date(y, m, dd+int(d/oneDay), 0, 0, 0, 0, t.Location())
+	}
+	start = t.Truncate(d)
+	return start, start.Add(d)
+}
+
+/* Must be called with lock held, before writing n bytes at time t. */
+func (l *Logger) checkRollover(n int64, t time.Time) {
+	if l.out.path == "" {
+		return
+	}
+	var suffix time.Time
+	if l.interval > 0 {
+		if l.periodEnd.IsZero() {
+			l.periodStart, l.periodEnd = period(t, l.interval)
+		}
+		if !t.Before(l.periodEnd) {
+			suffix = l.periodStart
+			l.periodStart, l.periodEnd = period(t, l.interval)
+		}
+	}
+	if suffix.IsZero() && l.maxSize > 0 && l.out.size > 0 && l.out.size+n > l.maxSize {
+		suffix = t
+	}
+	if suffix.IsZero() {
+		return
+	}
+	if err := l.rollover(suffix); err != nil {
+		if l.onError != nil {
+			l.onError(fmt.Errorf("rotate log file %s: %w", l.out.path, err))
+		} else {
+			fmt.Fprintf(os.Stderr, "Failed to rotate log file %s: %s\n", l.out.path, err)
+		}
+	}
+}
+
+func exists(name string) bool {
+	_, err := os.Lstat(name)
+	return !os.IsNotExist(err)
+}
+
+func backupName(path string, t time.Time) string {
+	name := path + "." + t.Format(backupTimeFormat)
+	for i := 1; ; i++ {
+		if !exists(name) && !exists(name+".gz") && !exists(name+".gz.tmp") {
+			return name
+		}
+		name = fmt.Sprintf("%s.%s.%d", path, t.Format(backupTimeFormat), i)
+	}
+}
+
+/* Must be called with lock held. Moves the current file aside and opens a fresh one at the same path. */
+func (l *Logger) rollover(t time.Time) (err error) {
+	path := l.out.path
+	name := backupName(path, t)
+	if err = os.Rename(path, name); err != nil {
+		return err
+	}
+	fl, err := l.openFile(path)
+	if err != nil {
+		return err
+	}
+	l.reopened(fl)
+	if l.compress || l.maxBackups > 0 || l.maxAge > 0 {
+		go l.afterRollover(path, name, l.compress, l.maxBackups, l.maxAge)
+	}
+	return nil
+}
+
+/* Runs in its own goroutine so compression never blocks the daemon. */
+func (l *Logger) afterRollover(path, name string, compress bool, maxBackups int, maxAge time.Duration) {
+	l.pruneLock.Lock()
+	defer l.pruneLock.Unlock()
+	if compress {
+		/* The file may already be pruned by an earlier rollover. */
+		if err := gzipFile(name); err != nil && !os.IsNotExist(err) {
+			l.Warnf("Failed to compress rotated log file %s: %s", name, err)
+		}
+	}
+	if maxBackups > 0 || maxAge > 0 {
+		l.prune(path, maxBackups, maxAge)
+	}
+}
+
+func (l *Logger) SetCompress(compress bool) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.compress = compress
+}
+
+/*
+ * Writes name.gz through a temporary file, so a partial archive never matches
+ * the backup pattern. The archive gets the permissions of name.
+ */
+func gzipFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	st, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	tmp := name + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, st.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	if _, err = io.Copy(zw, src); err == nil {
+		err = zw.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, name+".gz")
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Remove(name)
+}
+
+/* Zero disables the limit. */
+func (l *Logger) SetMaxBackups(n int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.maxBackups = n
+}
+
+/* Zero disables the limit. */
+func (l *Logger) SetMaxAge(d time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.maxAge = d
+}
+
+type backup struct {
+	path  string
+	time  time.Time
+	index int
+}
+
+/* Recognizes <base>.<time>[.<n>][.gz] as produced by backupName. */
+func parseBackup(base, name string) (b backup, ok bool) {
+	if !strings.HasPrefix(name, base+".") {
+		return b, false
+	}
+	rest := strings.TrimSuffix(name[len(base)+1:], ".gz")
+	if len(rest) < len(backupTimeFormat) {
+		return b, false
+	}
+	t, err := time.ParseInLocation(backupTimeFormat, rest[:len(backupTimeFormat)], time.Local)
+	if err != nil {
+		return b, false
+	}
+	b.time = t
+	if rest = rest[len(backupTimeFormat):]; rest != "" {
+		if rest[0] != '.' {
+			return b, false
+		}
+		if b.index, err = strconv.Atoi(rest[1:]); err != nil {
+			return b, false
+		}
+	}
+	return b, true
+}
+
+func listBackups(path string) (backups []backup, err error) {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	names, err := f.Readdirnames(-1)
+	f.Close()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if b, ok := parseBackup(base, name); ok {
+			b.path = filepath.Join(dir, name)
+			backups = append(backups, b)
+		}
+	}
+	/* Newest first. */
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].time.Equal(backups[j].time) {
+			return backups[i].index > backups[j].index
+		}
+		return backups[i].time.After(backups[j].time)
+	})
+	return backups, nil
+}
+
+/* Must be called with pruneLock held. */
+func (l *Logger) prune(path string, maxBackups int, maxAge time.Duration) {
+	backups, err := listBackups(path)
+	if err != nil {
+		l.Warnf("Failed to list rotated log files for %s: %s", path, err)
+		return
+	}
+	cutoff := time.Now().Add(-maxAge)
+	for i, b := range backups {
+		if (maxBackups > 0 && i >= maxBackups) || (maxAge > 0 && b.time.Before(cutoff)) {
+			if err := os.Remove(b.path); err != nil {
+				l.Warnf("Failed to remove rotated log file %s: %s", b.path, err)
+			}
+		}
+	}
+}
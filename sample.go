@@ -0,0 +1,58 @@
+package golog
+
+import (
+	"fmt"
+)
+
+/*
+ * Only every n-th of identical consecutive messages is written, followed by a
+ * summary of how many were suppressed. n <= 1 disables sampling.
+ */
+func (l *Logger) SetSampling(n int) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.flushRepeats()
+	l.sampling = n
+	l.last = nil
+}
+
+func sameMessage(a, b *Message) bool {
+	return a.level == b.level && a.caller.path == b.caller.path &&
+		a.caller.line == b.caller.line && a.message == b.message
+}
+
+/* Must be called with lock held. Reports whether msg should be written. */
+func (l *Logger) sample(msg *Message) bool {
+	if l.sampling <= 1 || msg.level == FATAL {
+		return true
+	}
+	if l.last != nil && sameMessage(l.last, msg) {
+		l.repeats++
+		if l.repeats%l.sampling != 0 {
+			l.suppressed++
+			return false
+		}
+		return true
+	}
+	l.flushRepeats()
+	/* msg goes back to the pool once written, keep a copy. */
+	last := *msg
+	l.last = &last
+	l.repeats = 0
+	return true
+}
+
+/* Must be called with lock held. */
+func (l *Logger) flushRepeats() {
+	if l.suppressed == 0 {
+		return
+	}
+	last := l.last
+	l.render(&Message{
+		caller:  last.caller,
+		message: fmt.Sprintf("Suppressed %d repeats of the last message", l.suppressed),
+		level:   last.level,
+		time:    last.time,
+	})
+	l.suppressed = 0
+}
@@ -0,0 +1,37 @@
+package golog
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+/*
+ * Writes everything queued, stops the daemon and closes the file opened by
+ * Open. If ctx is done first, an error with the number of messages still
+ * queued is returned and the daemon keeps draining in the background.
+ * Messages logged after Shutdown are written to stderr.
+ */
+func (l *Logger) Shutdown(ctx context.Context) error {
+	stopped := make(chan struct{})
+	go func() {
+		l.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		return fmt.Errorf("golog: shutdown with %d messages queued: %w", len(l.queue), ctx.Err())
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.out.close()
+	l.out = NewWriter(os.Stderr)
+	l.periodEnd = time.Time{}
+	return nil
+}
+
+func Shutdown(ctx context.Context) error {
+	return std.Shutdown(ctx)
+}
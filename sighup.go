@@ -0,0 +1,46 @@
+//go:build !windows && !plan9 && !js
+// +build !windows,!plan9,!js
+
+package golog
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+/*
+ * Calls Rotate on every SIGHUP, as logrotate sends after renaming, until the
+ * returned function is called. Other handlers of SIGHUP in the program still
+ * receive it. Rotation failures go to the error handler.
+ */
+func (l *Logger) HandleSIGHUP() (stop func()) {
+	sig := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(sig, syscall.SIGHUP)
+	go func() {
+		for {
+			select {
+			case <-sig:
+				if err := l.Rotate(); err != nil {
+					l.lock.Lock()
+					onError := l.onError
+					l.lock.Unlock()
+					if onError != nil {
+						onError(err)
+					}
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(sig)
+		close(done)
+	}
+}
+
+func HandleSIGHUP() func() {
+	return std.HandleSIGHUP()
+}
@@ -0,0 +1,13 @@
+//go:build windows || plan9 || js
+// +build windows plan9 js
+
+package golog
+
+/* There is no SIGHUP here, the returned function does nothing either. */
+func (l *Logger) HandleSIGHUP() (stop func()) {
+	return func() {}
+}
+
+func HandleSIGHUP() func() {
+	return std.HandleSIGHUP()
+}
@@ -0,0 +1,87 @@
+package golog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+func stackTrace() string {
+	buf := make([]byte, 4096)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+/* Messages at or above level carry a stack trace of the logging goroutine. INVALID disables it. */
+func (l *Logger) SetStackLevel(level Level) {
+	atomic.StoreInt32(&l.stackLevel, int32(level))
+}
+
+func SetStackLevel(level Level) {
+	std.SetStackLevel(level)
+}
+
+func (l *Logger) wantStack(level Level) bool {
+	stackLevel := Level(atomic.LoadInt32(&l.stackLevel))
+	return stackLevel != INVALID && level >= stackLevel
+}
+
+func (l *Logger) ErrorStack(msg string) {
+	l.post(ERROR, 0, msg, nil, nil, stackTrace)
+}
+
+func (l *Logger) FatalStack(msg string) {
+	l.post(FATAL, 0, msg, nil, nil, stackTrace)
+}
+
+func ErrorStack(msg string) {
+	std.post(ERROR, 0, msg, nil, nil, stackTrace)
+}
+
+func FatalStack(msg string) {
+	std.post(FATAL, 0, msg, nil, nil, stackTrace)
+}
+
+/*
+ * Renders the wrap chain below err, one cause per line, followed by the %+v
+ * output of errors that format themselves, like those carrying a stack trace.
+ */
+func errorDetails(err error) string {
+	var buf bytes.Buffer
+	for e := errors.Unwrap(err); e != nil; e = errors.Unwrap(e) {
+		fmt.Fprintf(&buf, "caused by: %s\n", e)
+	}
+	if _, ok := err.(fmt.Formatter); ok {
+		fmt.Fprintf(&buf, "%+v\n", err)
+	}
+	return buf.String()
+}
+
+func (l *Logger) logError(skip int, err error) {
+	if err == nil {
+		return
+	}
+	l.post(ERROR, skip+1, err.Error(), nil, nil, func() string {
+		details := errorDetails(err)
+		if l.wantStack(ERROR) {
+			details += stackTrace()
+		}
+		return details
+	})
+}
+
+/* Logs err at ERROR with its wrap chain and, if err carries one, its stack trace. */
+func (l *Logger) ErrorErr(err error) {
+	l.logError(0, err)
+}
+
+func ErrorErr(err error) {
+	std.logError(0, err)
+}
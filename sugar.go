@@ -0,0 +1,101 @@
+package golog
+
+import (
+	"fmt"
+)
+
+/*
+ * Pairs alternating keys and values into fields. Keys that aren't strings
+ * are printed with fmt, a value left without a key gets the key !BADKEY.
+ */
+func pairs(kv []interface{}) []Field {
+	if len(kv) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		if i+1 == len(kv) {
+			fields = append(fields, Field{Key: "!BADKEY", Value: kv[i]})
+			break
+		}
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		fields = append(fields, Field{Key: key, Value: kv[i+1]})
+	}
+	return fields
+}
+
+/* Like Fatal, with fields given as alternating keys and values: Fatalw("msg", "status", 200). */
+func (l *Logger) Fatalw(msg string, kv ...interface{}) {
+	l.log(FATAL, 0, msg, l.with(pairs(kv)))
+}
+
+func (l *Logger) Errorw(msg string, kv ...interface{}) {
+	l.log(ERROR, 0, msg, l.with(pairs(kv)))
+}
+
+func (l *Logger) Warnw(msg string, kv ...interface{}) {
+	l.log(WARN, 0, msg, l.with(pairs(kv)))
+}
+
+func (l *Logger) Infow(msg string, kv ...interface{}) {
+	l.log(INFO, 0, msg, l.with(pairs(kv)))
+}
+
+func (l *Logger) Debugw(msg string, kv ...interface{}) {
+	l.log(DEBUG, 0, msg, l.with(pairs(kv)))
+}
+
+func (l *Logger) Tracew(msg string, kv ...interface{}) {
+	l.log(TRACE, 0, msg, l.with(pairs(kv)))
+}
+
+func (e *Entry) Fatalw(msg string, kv ...interface{}) {
+	e.logger.log(FATAL, 0, msg, e.with(pairs(kv)))
+}
+
+func (e *Entry) Errorw(msg string, kv ...interface{}) {
+	e.logger.log(ERROR, 0, msg, e.with(pairs(kv)))
+}
+
+func (e *Entry) Warnw(msg string, kv ...interface{}) {
+	e.logger.log(WARN, 0, msg, e.with(pairs(kv)))
+}
+
+func (e *Entry) Infow(msg string, kv ...interface{}) {
+	e.logger.log(INFO, 0, msg, e.with(pairs(kv)))
+}
+
+func (e *Entry) Debugw(msg string, kv ...interface{}) {
+	e.logger.log(DEBUG, 0, msg, e.with(pairs(kv)))
+}
+
+func (e *Entry) Tracew(msg string, kv ...interface{}) {
+	e.logger.log(TRACE, 0, msg, e.with(pairs(kv)))
+}
+
+func Fatalw(msg string, kv ...interface{}) {
+	std.log(FATAL, 0, msg, std.with(pairs(kv)))
+}
+
+func Errorw(msg string, kv ...interface{}) {
+	std.log(ERROR, 0, msg, std.with(pairs(kv)))
+}
+
+func Warnw(msg string, kv ...interface{}) {
+	std.log(WARN, 0, msg, std.with(pairs(kv)))
+}
+
+func Infow(msg string, kv ...interface{}) {
+	std.log(INFO, 0, msg, std.with(pairs(kv)))
+}
+
+func Debugw(msg string, kv ...interface{}) {
+	std.log(DEBUG, 0, msg, std.with(pairs(kv)))
+}
+
+func Tracew(msg string, kv ...interface{}) {
+	std.log(TRACE, 0, msg, std.with(pairs(kv)))
+}
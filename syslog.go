@@ -0,0 +1,67 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package golog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/syslog"
+)
+
+type syslogWriter struct {
+	w *syslog.Writer
+}
+
+/*
+ * Returns an output for New, OpenWriter or AddOutput. Syslog records the time
+ * and severity itself, so only caller, prefix, message and fields are sent.
+ */
+func NewSyslog(network, addr, tag string) (w io.Writer, err error) {
+	sw, err := syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogWriter{w: sw}, nil
+}
+
+func (s *syslogWriter) Write(p []byte) (n int, err error) {
+	return s.w.Write(p)
+}
+
+func (s *syslogWriter) Close() error {
+	return s.w.Close()
+}
+
+func (s *syslogWriter) writeMessage(l *Logger, o *FileLog, lines *lineCache) (n int, err error) {
+	msg := lines.msg
+	var buf bytes.Buffer
+	if msg.hasCaller() {
+		fmt.Fprintf(&buf, "[%s:%d] ", l.file(msg), msg.caller.line)
+	}
+	if msg.name != "" {
+		fmt.Fprintf(&buf, "[%s] ", msg.name)
+	}
+	fmt.Fprintf(&buf, "%s%s%s", l.prefix, msg.prefix, msg.message)
+	writeTextFields(&buf, msg.fields, nil)
+	line := buf.String()
+	switch msg.level {
+	case TRACE, DEBUG:
+		err = s.w.Debug(line)
+	case INFO:
+		err = s.w.Info(line)
+	case WARN:
+		err = s.w.Warning(line)
+	case ERROR:
+		err = s.w.Err(line)
+	case FATAL:
+		err = s.w.Crit(line)
+	default:
+		err = s.w.Notice(line)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(line), nil
+}
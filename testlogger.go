@@ -0,0 +1,57 @@
+package golog
+
+import (
+	"io"
+	"strings"
+)
+
+/* Satisfied by testing.TB, without pulling package testing into programs. */
+type TestingT interface {
+	Helper()
+	Log(args ...interface{})
+}
+
+/* testing.TB since Go 1.25, writes test output without a source location. */
+type testOutput interface {
+	Output() io.Writer
+}
+
+type testWriter struct {
+	t TestingT
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Helper()
+	return w.log(p)
+}
+
+/* Bypasses the buffer, every message is one t.Log call. */
+func (w testWriter) writeMessage(l *Logger, o *FileLog, lines *lineCache) (int, error) {
+	w.t.Helper()
+	return w.log(l.line(o, lines))
+}
+
+/*
+ * Lines carry their own caller, so Output is preferred where available. t.Log
+ * would prefix them with the golog frame above the helpers instead.
+ */
+func (w testWriter) log(p []byte) (int, error) {
+	w.t.Helper()
+	if to, ok := w.t.(testOutput); ok {
+		return to.Output().Write(p)
+	}
+	w.t.Log(strings.TrimSuffix(string(p), "\n"))
+	return len(p), nil
+}
+
+/*
+ * Returns a logger writing synchronously to the test output, so its lines
+ * show up under the test and in order with the test's own output. Its daemon
+ * is stopped right away, no goroutine outlives the test.
+ */
+func NewTestLogger(t TestingT) *Logger {
+	l := New(testWriter{t})
+	l.SetSync(true)
+	l.Stop()
+	return l
+}
@@ -0,0 +1,62 @@
+package golog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+type fakeT struct {
+	logs []string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Log(args ...interface{}) {
+	t.logs = append(t.logs, args[0].(string))
+}
+
+func TestNewTestLogger(t *testing.T) {
+	ft := new(fakeT)
+	l := NewTestLogger(ft)
+	if l.has_daemon {
+		t.Error("NewTestLogger left its daemon running")
+	}
+	l.Info("text")
+	l.SetOutputFormat(testWriter{ft}, JSON)
+	l.Info("json")
+	if len(ft.logs) != 2 {
+		t.Fatalf("got %q, want 2 lines", ft.logs)
+	}
+	if !strings.HasSuffix(ft.logs[0], "] text") {
+		t.Errorf("first line = %q, want a text line", ft.logs[0])
+	}
+	if !strings.HasPrefix(ft.logs[1], "{") || !strings.Contains(ft.logs[1], `"json"`) {
+		t.Errorf("second line = %q, want a JSON line", ft.logs[1])
+	}
+}
+
+/* Satisfied by testing.TB since Go 1.25, see testOutput. */
+type fakeOutputT struct {
+	fakeT
+	out syncBuffer
+}
+
+func (t *fakeOutputT) Output() io.Writer {
+	return &t.out
+}
+
+func TestNewTestLoggerOutput(t *testing.T) {
+	ft := new(fakeOutputT)
+	l := NewTestLogger(ft)
+	l.SetTimeFormat("")
+	line := here() + 1
+	l.Info("line")
+	if len(ft.logs) != 0 {
+		t.Errorf("Log called with %q, want only Output used", ft.logs)
+	}
+	if got, want := ft.out.String(), fmt.Sprintf("[ INFO][testlogger_test.go:%d] line\n", line); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,29 @@
+package golog
+
+import (
+	"io"
+	"strings"
+)
+
+type levelWriter struct {
+	logger *Logger
+	level  Level
+}
+
+/*
+ * The returned writer is meant for log.SetOutput, the reported caller is the
+ * one invoking the standard log functions.
+ */
+func (l *Logger) LevelWriter(level Level) io.Writer {
+	return &levelWriter{logger: l, level: level}
+}
+
+func LevelWriter(level Level) io.Writer {
+	return std.LevelWriter(level)
+}
+
+func (w *levelWriter) Write(p []byte) (n int, err error) {
+	/* Write <- log.(*Logger).output <- log.Printf and friends <- caller. */
+	w.logger.log(w.level, 2, strings.TrimSuffix(string(p), "\n"), nil)
+	return len(p), nil
+}